@@ -0,0 +1,180 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path"
+	"sort"
+	"strconv"
+	"text/template"
+)
+
+const ormPkg = "WebFrame/orm"
+
+type file struct {
+	Package string
+	// Imports are the import specs used by the field types
+	Imports []string
+	OrmPkg  string
+	Models  []model
+}
+
+type model struct {
+	Name   string
+	Fields []field
+}
+
+type field struct {
+	Name string
+	Type string
+}
+
+var tpl = template.Must(template.New("ormgen").Parse(`// Code generated by ormgen. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+{{- range .Imports}}
+	{{.}}
+{{- end}}
+	"{{.OrmPkg}}"
+)
+{{range .Models}}
+// {{.Name}}Columns contains the columns of {{.Name}}
+var {{.Name}}Columns = struct {
+{{- range .Fields}}
+	{{.Name}} orm.TypedColumn[{{.Type}}]
+{{- end}}
+}{
+{{- range .Fields}}
+	{{.Name}}: orm.TC[{{.Type}}]("{{.Name}}"),
+{{- end}}
+}
+
+// New{{.Name}}Selector creates a Selector of {{.Name}} on a DB or Tx
+var New{{.Name}}Selector = orm.NewSelector[{{.Name}}]
+{{end}}`))
+
+// gen parses the source and generates the accessors of the structs in names.
+// If names is empty, all exported structs are used
+func gen(filename string, src []byte, names []string) ([]byte, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, filename, src, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, err
+	}
+	wanted := make(map[string]bool, len(names))
+	for _, n := range names {
+		wanted[n] = true
+	}
+
+	res := file{Package: f.Name.Name, OrmPkg: ormPkg}
+	pkgs := make(map[string]bool)
+	for _, decl := range f.Decls {
+		gd, ok := decl.(*ast.GenDecl)
+		if !ok || gd.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gd.Specs {
+			ts := spec.(*ast.TypeSpec)
+			st, ok := ts.Type.(*ast.StructType)
+			// generic structs can not be registered as models
+			if !ok || ts.TypeParams != nil {
+				continue
+			}
+			if len(wanted) > 0 {
+				if !wanted[ts.Name.Name] {
+					continue
+				}
+				delete(wanted, ts.Name.Name)
+			} else if !ts.Name.IsExported() {
+				continue
+			}
+			res.Models = append(res.Models, model{Name: ts.Name.Name, Fields: fields(st, pkgs)})
+		}
+	}
+	for n := range wanted {
+		return nil, fmt.Errorf("ormgen: struct %s not found in %s", n, filename)
+	}
+	res.Imports = imports(f, pkgs)
+
+	buf := &bytes.Buffer{}
+	if err = tpl.Execute(buf, res); err != nil {
+		return nil, err
+	}
+	return format.Source(buf.Bytes())
+}
+
+// fields returns the exported fields in the order they are defined,
+// which is the same as model.Registry. The packages referred by the field types are added to pkgs
+func fields(st *ast.StructType, pkgs map[string]bool) []field {
+	res := make([]field, 0, len(st.Fields.List))
+	for _, fd := range st.Fields.List {
+		names := make([]string, 0, len(fd.Names))
+		if len(fd.Names) == 0 {
+			// embedded field, it is named after its type
+			names = append(names, embeddedName(fd.Type))
+		}
+		for _, n := range fd.Names {
+			names = append(names, n.Name)
+		}
+		for _, n := range names {
+			if n == "" || !ast.IsExported(n) {
+				continue
+			}
+			res = append(res, field{Name: n, Type: types.ExprString(fd.Type)})
+			ast.Inspect(fd.Type, func(node ast.Node) bool {
+				if sel, ok := node.(*ast.SelectorExpr); ok {
+					if id, ok := sel.X.(*ast.Ident); ok {
+						pkgs[id.Name] = true
+					}
+				}
+				return true
+			})
+		}
+	}
+	return res
+}
+
+// imports returns the import specs of f whose package names are in pkgs
+func imports(f *ast.File, pkgs map[string]bool) []string {
+	res := make([]string, 0, len(pkgs))
+	for _, imp := range f.Imports {
+		p, err := strconv.Unquote(imp.Path.Value)
+		if err != nil {
+			continue
+		}
+		name := path.Base(p)
+		if imp.Name != nil {
+			name = imp.Name.Name
+		}
+		if !pkgs[name] {
+			continue
+		}
+		if imp.Name != nil {
+			res = append(res, imp.Name.Name+" "+imp.Path.Value)
+		} else {
+			res = append(res, imp.Path.Value)
+		}
+	}
+	sort.Strings(res)
+	return res
+}
+
+func embeddedName(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.StarExpr:
+		return embeddedName(e.X)
+	case *ast.SelectorExpr:
+		return e.Sel.Name
+	case *ast.Ident:
+		return e.Name
+	default:
+		return ""
+	}
+}
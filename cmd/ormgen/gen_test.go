@@ -0,0 +1,94 @@
+package main
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGen(t *testing.T) {
+	src, err := os.ReadFile("testdata/user.go")
+	require.NoError(t, err)
+	want, err := os.ReadFile("testdata/user_gen.golden")
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name    string
+		types   []string
+		want    string
+		wantErr string
+	}{
+		{
+			name: "all exported structs",
+			want: string(want),
+		},
+		{
+			name:    "unknown struct",
+			types:   []string{"Unknown"},
+			wantErr: "ormgen: struct Unknown not found in user.go",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := gen("user.go", src, tc.types)
+			if tc.wantErr != "" {
+				assert.EqualError(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, string(res))
+		})
+	}
+}
+
+func TestGen_Types(t *testing.T) {
+	src, err := os.ReadFile("testdata/user.go")
+	require.NoError(t, err)
+	res, err := gen("user.go", src, []string{"helper"})
+	require.NoError(t, err)
+	assert.Contains(t, string(res), `Name: orm.TC[string]("Name"),`)
+	assert.NotContains(t, string(res), "secret")
+	assert.NotContains(t, string(res), "UserColumns")
+	// 没有用到 database/sql
+	assert.NotContains(t, string(res), "database/sql")
+}
+
+// TestGen_Compile 确保生成的代码能够通过编译
+func TestGen_Compile(t *testing.T) {
+	if testing.Short() {
+		t.Skip("compiling the generated code is slow")
+	}
+	src, err := os.ReadFile("testdata/user.go")
+	require.NoError(t, err)
+	res, err := gen("user.go", src, nil)
+	require.NoError(t, err)
+
+	// 放在 module 里面才能引用 WebFrame/orm
+	dir, err := os.MkdirTemp("testdata", "compile")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = os.RemoveAll(dir)
+	})
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "user.go"), src, 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "user_gen.go"), res, 0o644))
+	// 使用生成的代码，类型不对的时候编译失败
+	use := `package testdata
+
+import "WebFrame/orm"
+
+var _ = NewUserSelector((*orm.DB)(nil)).Where(UserColumns.FirstName.EQ("Tom"), UserColumns.Age.In(1, 2))
+`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "use.go"), []byte(use), 0o644))
+	out, err := exec.Command("go", "vet", "./"+dir).CombinedOutput()
+	require.NoError(t, err, string(out))
+
+	wrongType := strings.Replace(use, `EQ("Tom")`, `EQ(123)`, 1)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "use.go"), []byte(wrongType), 0o644))
+	out, err = exec.Command("go", "vet", "./"+dir).CombinedOutput()
+	require.Error(t, err)
+	assert.Contains(t, string(out), "cannot use 123")
+}
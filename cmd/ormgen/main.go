@@ -0,0 +1,48 @@
+// ormgen generates typed column accessors for orm models.
+//
+// Usage:
+//
+//	//go:generate ormgen -src user.go
+//
+// For every struct in src, ormgen generates a XXXColumns variable holding one
+// orm.TypedColumn per exported field and a NewXXXSelector function, so that
+// UserColumns.FirstName.EQ("x") replaces orm.C("FirstName").EQ("x"),
+// and both typos in field names and values of the wrong type become compile errors.
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"strings"
+)
+
+func main() {
+	src := flag.String("src", "", "the Go source file containing the models")
+	dst := flag.String("dst", "", "the output file, default is <src>_gen.go")
+	types := flag.String("types", "", "comma separated struct names, default is all exported structs")
+	flag.Parse()
+	if *src == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *dst == "" {
+		*dst = strings.TrimSuffix(*src, ".go") + "_gen.go"
+	}
+	var names []string
+	if *types != "" {
+		names = strings.Split(*types, ",")
+	}
+
+	in, err := os.ReadFile(*src)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	out, err := gen(*src, in, names)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	if err = os.WriteFile(*dst, out, 0o644); err != nil {
+		log.Fatalln(err)
+	}
+}
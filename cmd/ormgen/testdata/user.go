@@ -0,0 +1,23 @@
+package testdata
+
+import "database/sql"
+
+type User struct {
+	Id         int64 `orm:"primary_key=true"`
+	FirstName  string
+	Age, Score int8
+	LastName   *sql.NullString
+	// 非导出字段不会生成
+	password string
+}
+
+type Order struct {
+	Id     int64
+	UserId int64
+}
+
+type helper struct {
+	Name string
+	// 非导出字段不会生成
+	secret string
+}
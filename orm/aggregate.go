@@ -77,3 +77,8 @@ func Count(c string) Aggregate {
 		arg: c,
 	}
 }
+
+// Over turns the aggregate into a window function
+func (a Aggregate) Over() WindowExpr {
+	return WindowExpr{fn: Aggregate{fn: a.fn, arg: a.arg}}
+}
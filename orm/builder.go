@@ -3,18 +3,70 @@ package orm
 import (
 	"WebFrame/orm/internal/errs"
 	"WebFrame/orm/model"
-	"strings"
 )
 
 type builder struct {
 	core
-	sb      strings.Builder
+	sb      sqlBuffer
 	args    []any
 	dialect Dialect
 	quoter  byte
 	model   *model.Model
 }
 
+// buildQuery resets the builder and runs build, which writes the whole statement.
+// If the shape cache is enabled, build runs in walk mode first to collect the args,
+// and the SQL is only written when the shape is not cached
+func (b *builder) buildQuery(build func() error) (*Query, error) {
+	b.reset()
+	if b.shapes != nil {
+		b.shapes.mu.RLock()
+		b.sb.walkMode(b.shapes.root)
+		err := build()
+		n := b.sb.node
+		b.shapes.mu.RUnlock()
+		if err != nil {
+			return nil, err
+		}
+		if n != nil && n.end {
+			return &Query{
+				SQL:  n.sql,
+				Args: b.args,
+			}, nil
+		}
+		b.reset()
+		b.sb.recordMode()
+	}
+	if err := build(); err != nil {
+		return nil, err
+	}
+	q := &Query{
+		SQL:  b.sb.String(),
+		Args: b.args,
+	}
+	if b.shapes != nil {
+		b.shapes.add(b.sb.frags, q.SQL)
+	}
+	return q, nil
+}
+
+// clone returns a builder sharing the configuration of b but nothing written,
+// it is used by the copies of the builders
+func (b *builder) clone() builder {
+	return builder{
+		core:    b.core,
+		dialect: b.dialect,
+		quoter:  b.quoter,
+		model:   b.model,
+	}
+}
+
+// reset makes it possible to call Build more than once
+func (b *builder) reset() {
+	b.sb.reset()
+	b.args = nil
+}
+
 func (b *builder) buildColumn(fd string) error {
 	meta, ok := b.model.FieldMap[fd]
 	if !ok {
@@ -65,20 +117,158 @@ func (b *builder) buildExpression(e Expression) error {
 	case value:
 		b.sb.WriteByte('?')
 		b.addArgs(exp.val)
+	case valuesExpr:
+		b.buildValues(exp)
 	case RawExpr:
 		b.raw(exp)
-	//case MathExpr:
-	//	return b.buildBinaryExpr(binaryExpr(exp))
-	//case Predicate:
-	//	return b.buildBinaryExpr(binaryExpr(exp))
-	//case binaryExpr:
-	//	return b.buildBinaryExpr(exp)
+	case MathExpr:
+		return b.buildBinaryExpr(binaryExpr(exp))
+	case Predicate:
+		return b.buildBinaryExpr(binaryExpr(exp))
+	case binaryExpr:
+		return b.buildBinaryExpr(exp)
+	case CaseExpr:
+		return b.buildCase(exp)
+	case FuncExpr:
+		return b.buildFunc(exp)
+	case WindowExpr:
+		return b.buildWindow(exp)
 	default:
 		return errs.NewErrUnsupportedExpressionType(exp)
 	}
 	return nil
 }
 
+func (b *builder) buildValues(v valuesExpr) {
+	if len(v.vals) == 0 {
+		// IN () is invalid, and nothing equals NULL
+		b.sb.WriteString("(NULL)")
+		return
+	}
+	b.sb.WriteByte('(')
+	for i := range v.vals {
+		if i > 0 {
+			b.sb.WriteByte(',')
+		}
+		b.sb.WriteByte('?')
+	}
+	b.sb.WriteByte(')')
+	b.addArgs(v.vals...)
+}
+
+func (b *builder) buildBinaryExpr(e binaryExpr) error {
+	if err := b.buildSubExpr(e.left); err != nil {
+		return err
+	}
+	b.sb.WriteByte(' ')
+	b.sb.WriteString(e.op.String())
+	b.sb.WriteByte(' ')
+	return b.buildSubExpr(e.right)
+}
+
+// buildSubExpr wraps the nested binary expressions with brackets
+func (b *builder) buildSubExpr(subExpr Expression) error {
+	switch sub := subExpr.(type) {
+	case MathExpr:
+		return b.buildBracketed(binaryExpr(sub))
+	case Predicate:
+		return b.buildBracketed(binaryExpr(sub))
+	case binaryExpr:
+		return b.buildBracketed(sub)
+	default:
+		return b.buildExpression(sub)
+	}
+}
+
+func (b *builder) buildBracketed(e binaryExpr) error {
+	b.sb.WriteByte('(')
+	if err := b.buildBinaryExpr(e); err != nil {
+		return err
+	}
+	b.sb.WriteByte(')')
+	return nil
+}
+
+func (b *builder) buildCase(c CaseExpr) error {
+	b.sb.WriteString("CASE")
+	for _, w := range c.whens {
+		b.sb.WriteString(" WHEN ")
+		if err := b.buildExpression(w.cond); err != nil {
+			return err
+		}
+		b.sb.WriteString(" THEN ")
+		if err := b.buildExpression(w.then); err != nil {
+			return err
+		}
+	}
+	if c.els != nil {
+		b.sb.WriteString(" ELSE ")
+		if err := b.buildExpression(c.els); err != nil {
+			return err
+		}
+	}
+	b.sb.WriteString(" END")
+	return nil
+}
+
+func (b *builder) buildFunc(f FuncExpr) error {
+	b.sb.WriteString(f.fn)
+	b.sb.WriteByte('(')
+	for i, arg := range f.args {
+		if i > 0 {
+			b.sb.WriteByte(',')
+		}
+		if err := b.buildExpression(arg); err != nil {
+			return err
+		}
+	}
+	b.sb.WriteByte(')')
+	return nil
+}
+
+func (b *builder) buildWindow(w WindowExpr) error {
+	if err := b.buildExpression(w.fn); err != nil {
+		return err
+	}
+	b.sb.WriteString(" OVER (")
+	if len(w.partitionBy) > 0 {
+		b.sb.WriteString("PARTITION BY ")
+		for i, c := range w.partitionBy {
+			if i > 0 {
+				b.sb.WriteByte(',')
+			}
+			if err := b.buildColumn(c.name); err != nil {
+				return err
+			}
+		}
+	}
+	if len(w.orderBy) > 0 {
+		if len(w.partitionBy) > 0 {
+			b.sb.WriteByte(' ')
+		}
+		b.sb.WriteString("ORDER BY ")
+		if err := b.buildOrderBy(w.orderBy); err != nil {
+			return err
+		}
+	}
+	b.sb.WriteByte(')')
+	return nil
+}
+
+func (b *builder) buildOrderBy(obs []OrderBy) error {
+	for i, ob := range obs {
+		if i > 0 {
+			b.sb.WriteByte(',')
+		}
+		if err := b.buildColumn(ob.col); err != nil {
+			return err
+		}
+		b.sb.WriteByte(' ')
+		b.sb.WriteString(ob.order)
+	}
+	return nil
+}
+
 func (b *builder) buildAggregate(a Aggregate, useAlias bool) error {
 	b.sb.WriteString(a.fn)
 	b.sb.WriteByte('(')
@@ -0,0 +1,203 @@
+package orm
+
+import (
+	"context"
+	"database/sql"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"regexp"
+	"testing"
+)
+
+func TestBuilder_BuildTwice(t *testing.T) {
+	db := memoryDB(t)
+	s := NewSelector[TestModel](db).Where(C("Id").EQ(1))
+	q1, err := s.Build()
+	require.NoError(t, err)
+	q2, err := s.Build()
+	require.NoError(t, err)
+	assert.Equal(t, q1, q2)
+	assert.Equal(t, &Query{
+		SQL:  "SELECT * FROM `test_model` WHERE `id` = ?;",
+		Args: []any{1},
+	}, q2)
+}
+
+func TestBuilder_ShapeCache(t *testing.T) {
+	db := memoryDB(t, DBWithShapeCache(16))
+	testCases := []struct {
+		name      string
+		q         QueryBuilder
+		wantQuery *Query
+		// the number of shapes after building q
+		wantShapes int
+	}{
+		{
+			name: "select",
+			q:    NewSelector[TestModel](db).Where(C("Id").EQ(1)),
+			wantQuery: &Query{
+				SQL:  "SELECT * FROM `test_model` WHERE `id` = ?;",
+				Args: []any{1},
+			},
+			wantShapes: 1,
+		},
+		{
+			name: "select same shape",
+			q:    NewSelector[TestModel](db).Where(C("Id").EQ(2)),
+			wantQuery: &Query{
+				SQL:  "SELECT * FROM `test_model` WHERE `id` = ?;",
+				Args: []any{2},
+			},
+			wantShapes: 1,
+		},
+		{
+			name: "select another shape",
+			q:    NewSelector[TestModel](db).Where(C("Age").EQ(2)),
+			wantQuery: &Query{
+				SQL:  "SELECT * FROM `test_model` WHERE `age` = ?;",
+				Args: []any{2},
+			},
+			wantShapes: 2,
+		},
+		{
+			name: "insert",
+			q: NewInserter[TestModel](db).Values(&TestModel{Id: 1}).Columns("Id", "Age").
+				OnDuplicateKey().Update(Assign("Age", 3)),
+			wantQuery: &Query{
+				SQL:  "INSERT INTO `test_model`(`id`, `age`) VALUES(?,?) ON DUPLICATE KEY UPDATE `age`=?;",
+				Args: []any{int64(1), int8(0), 3},
+			},
+			wantShapes: 3,
+		},
+		{
+			name: "insert same shape",
+			q: NewInserter[TestModel](db).Values(&TestModel{Id: 2, Age: 4}).Columns("Id", "Age").
+				OnDuplicateKey().Update(Assign("Age", 5)),
+			wantQuery: &Query{
+				SQL:  "INSERT INTO `test_model`(`id`, `age`) VALUES(?,?) ON DUPLICATE KEY UPDATE `age`=?;",
+				Args: []any{int64(2), int8(4), 5},
+			},
+			wantShapes: 3,
+		},
+		{
+			name: "delete",
+			q:    NewDeleter[TestModel](db).Where(C("Id").EQ(1)),
+			wantQuery: &Query{
+				SQL:  "DELETE FROM `test_model` WHERE `id` = ?;",
+				Args: []any{1},
+			},
+			wantShapes: 4,
+		},
+		{
+			name: "union",
+			q: NewSelector[TestModel](db).Where(C("Id").EQ(1)).
+				Union(NewSelector[TestModel](db).Where(C("Id").EQ(2))),
+			wantQuery: &Query{
+				SQL:  "SELECT * FROM `test_model` WHERE `id` = ? UNION SELECT * FROM `test_model` WHERE `id` = ?;",
+				Args: []any{1, 2},
+			},
+			wantShapes: 5,
+		},
+		{
+			// the prefix of a cached shape is not a hit
+			name: "prefix of another shape",
+			q:    NewSelector[TestModel](db),
+			wantQuery: &Query{
+				SQL: "SELECT * FROM `test_model`;",
+			},
+			wantShapes: 6,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			q, err := tc.q.Build()
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantQuery, q)
+			assert.Equal(t, tc.wantShapes, db.shapes.Len())
+		})
+	}
+}
+
+func TestBuilder_ShapeCacheFull(t *testing.T) {
+	db := memoryDB(t, DBWithShapeCache(1))
+	_, err := NewSelector[TestModel](db).Where(C("Id").EQ(1)).Build()
+	require.NoError(t, err)
+	// the cache is cleared to add the new shape
+	q, err := NewSelector[TestModel](db).Where(C("Age").EQ(2)).Build()
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM `test_model` WHERE `age` = ?;", q.SQL)
+	assert.Equal(t, 1, db.shapes.Len())
+	q, err = NewSelector[TestModel](db).Where(C("Id").EQ(3)).Build()
+	require.NoError(t, err)
+	assert.Equal(t, &Query{SQL: "SELECT * FROM `test_model` WHERE `id` = ?;", Args: []any{3}}, q)
+	assert.Equal(t, 1, db.shapes.Len())
+}
+
+type countBuilder struct {
+	QueryBuilder
+	cnt int
+}
+
+func (c *countBuilder) Build() (*Query, error) {
+	c.cnt++
+	return c.QueryBuilder.Build()
+}
+
+func TestQueryContext_Query(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = mockDB.Close() }()
+	// every middleware reads the query
+	var ms []Middleware
+	for i := 0; i < 3; i++ {
+		ms = append(ms, func(next HandleFunc) HandleFunc {
+			return func(ctx context.Context, qc *QueryContext) *QueryResult {
+				_, er := qc.Query()
+				require.NoError(t, er)
+				return next(ctx, qc)
+			}
+		})
+	}
+	db, err := OpenDB(mockDB)
+	require.NoError(t, err)
+	db.ms = ms
+
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `test_model` WHERE `id` = ?;")).
+		WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+	b := &countBuilder{QueryBuilder: NewDeleter[TestModel](db).Where(C("Id").EQ(1))}
+	res := exec(context.Background(), db, db.core, &QueryContext{
+		Builder: b,
+		Type:    "DELETE",
+	})
+	require.NoError(t, res.Err())
+	assert.Equal(t, 1, b.cnt)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func BenchmarkSelector_Build(b *testing.B) {
+	benchmarks := []struct {
+		name string
+		opts []DBOption
+	}{
+		{name: "no cache"},
+		{name: "shape cache", opts: []DBOption{DBWithShapeCache(16)}},
+	}
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			db, err := OpenDB(&sql.DB{}, bm.opts...)
+			require.NoError(b, err)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				_, err = NewSelector[TestModel](db).
+					Select(C("Id"), C("FirstName"), C("Age")).
+					Where(C("Age").GT(18).And(C("Age").LT(35)).Or(C("FirstName").EQ("Tom"))).
+					GroupBy(C("Age")).Having(Avg("Age").GT(20)).
+					Limit(10).Offset(i).Build()
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -24,6 +24,13 @@ type value struct {
 
 func (c value) expr() {}
 
+// valuesExpr is the list of IN
+type valuesExpr struct {
+	vals []any
+}
+
+func (valuesExpr) expr() {}
+
 func valueOf(val any) value {
 	return value{
 		val: val,
@@ -60,3 +67,64 @@ func (c Column) GT(arg any) Predicate {
 		right: exprOf(arg),
 	}
 }
+
+// not equal
+func (c Column) NEQ(arg any) Predicate {
+	return Predicate{
+		left:  c,
+		op:    opNEQ,
+		right: exprOf(arg),
+	}
+}
+
+// less than or equal
+func (c Column) LTE(arg any) Predicate {
+	return Predicate{
+		left:  c,
+		op:    opLTE,
+		right: exprOf(arg),
+	}
+}
+
+// greater than or equal
+func (c Column) GTE(arg any) Predicate {
+	return Predicate{
+		left:  c,
+		op:    opGTE,
+		right: exprOf(arg),
+	}
+}
+
+// Like matches the pattern, % and _ in pattern are wildcards
+func (c Column) Like(pattern string) Predicate {
+	return Predicate{
+		left:  c,
+		op:    opLike,
+		right: valueOf(pattern),
+	}
+}
+
+// In matches any of vals, it matches nothing if vals is empty
+func (c Column) In(vals ...any) Predicate {
+	return Predicate{
+		left:  c,
+		op:    opIn,
+		right: valuesExpr{vals: vals},
+	}
+}
+
+func (c Column) Add(val any) MathExpr {
+	return MathExpr{
+		left:  c,
+		op:    opAdd,
+		right: exprOf(val),
+	}
+}
+
+func (c Column) Multiply(val any) MathExpr {
+	return MathExpr{
+		left:  c,
+		op:    opMulti,
+		right: exprOf(val),
+	}
+}
@@ -13,24 +13,54 @@ type core struct {
 	dialect    Dialect
 	valCreator valuer.Creator
 	ms         []Middleware
+	// shapes caches the SQL by the shape of the query, nil means disabled
+	shapes *shapeCache
+}
+
+// modelOf returns the model of T,
+// or nil if T is not a model, such as RawQuerier[int]
+func modelOf[T any](c core) *model.Model {
+	m, err := c.r.Get(new(T))
+	if err != nil {
+		return nil
+	}
+	return m
+}
+
+// invoke runs qc through the middlewares, and handler at last
+func invoke(ctx context.Context, c core, sess session, qc *QueryContext, handler HandleFunc) *QueryResult {
+	qc.sess = sess
+	ms := c.ms
+	for i := len(ms) - 1; i >= 0; i-- {
+		handler = ms[i](handler)
+	}
+	return handler(ctx, qc)
 }
 
 func getHandler[T any](ctx context.Context,
 	sess session,
 	c core,
 	qc *QueryContext) *QueryResult {
-	q, err := qc.Builder.Build()
+	q, err := qc.Query()
 	if err != nil {
 		return &QueryResult{
 			Err: err,
 		}
 	}
+	if dryRun(ctx, q) {
+		return &QueryResult{
+			Err: errs.ErrDryRun,
+		}
+	}
 	rows, err := sess.queryContext(ctx, q.SQL, q.Args...)
 	if err != nil {
 		return &QueryResult{
 			Err: err,
 		}
 	}
+	defer func() {
+		_ = rows.Close()
+	}()
 
 	if !rows.Next() {
 		return &QueryResult{
@@ -57,29 +87,124 @@ func get[T any](ctx context.Context, c core, sess session, qc *QueryContext) *Qu
 	var handler HandleFunc = func(ctx context.Context, qc *QueryContext) *QueryResult {
 		return getHandler[T](ctx, sess, c, qc)
 	}
-	ms := c.ms
-	for i := len(ms) - 1; i >= 0; i-- {
-		handler = ms[i](handler)
+	if qc.Model == nil {
+		qc.Model = modelOf[T](c)
 	}
-	return handler(ctx, qc)
+	return invoke(ctx, c, sess, qc, handler)
+}
+
+func getMultiHandler[T any](ctx context.Context,
+	sess session,
+	c core,
+	qc *QueryContext) *QueryResult {
+	q, err := qc.Query()
+	if err != nil {
+		return &QueryResult{
+			Err: err,
+		}
+	}
+	if dryRun(ctx, q) {
+		return &QueryResult{
+			Err: errs.ErrDryRun,
+		}
+	}
+	rows, err := sess.queryContext(ctx, q.SQL, q.Args...)
+	if err != nil {
+		return &QueryResult{
+			Err: err,
+		}
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	res := make([]*T, 0, 16)
+	for rows.Next() {
+		tp := new(T)
+		meta, er := c.r.Get(tp)
+		if er != nil {
+			return &QueryResult{
+				Err: er,
+			}
+		}
+		val := c.valCreator(tp, meta)
+		if er = val.SetColumns(rows); er != nil {
+			return &QueryResult{
+				Err: er,
+			}
+		}
+		res = append(res, tp)
+	}
+	return &QueryResult{
+		Res: res,
+		Err: rows.Err(),
+	}
+}
+
+func getMulti[T any](ctx context.Context, c core, sess session, qc *QueryContext) *QueryResult {
+	var handler HandleFunc = func(ctx context.Context, qc *QueryContext) *QueryResult {
+		return getMultiHandler[T](ctx, sess, c, qc)
+	}
+	if qc.Model == nil {
+		qc.Model = modelOf[T](c)
+	}
+	return invoke(ctx, c, sess, qc, handler)
+}
+
+// getValue is used when the query returns a single column, such as COUNT(*),
+// and the column is scanned into V directly instead of a model
+func getValue[V any](ctx context.Context, c core, sess session, qc *QueryContext) *QueryResult {
+	var handler HandleFunc = func(ctx context.Context, qc *QueryContext) *QueryResult {
+		q, err := qc.Query()
+		if err != nil {
+			return &QueryResult{
+				Err: err,
+			}
+		}
+		if dryRun(ctx, q) {
+			return &QueryResult{
+				Err: errs.ErrDryRun,
+			}
+		}
+		rows, err := sess.queryContext(ctx, q.SQL, q.Args...)
+		if err != nil {
+			return &QueryResult{
+				Err: err,
+			}
+		}
+		defer func() {
+			_ = rows.Close()
+		}()
+		if !rows.Next() {
+			return &QueryResult{
+				Err: errs.ErrNoRows,
+			}
+		}
+		var v V
+		err = rows.Scan(&v)
+		return &QueryResult{
+			Res: v,
+			Err: err,
+		}
+	}
+	return invoke(ctx, c, sess, qc, handler)
 }
 
 func exec(ctx context.Context, sess session, c core, qc *QueryContext) Result {
 	var handler HandleFunc = func(ctx context.Context, qc *QueryContext) *QueryResult {
-		q, err := qc.Builder.Build()
+		q, err := qc.Query()
 		if err != nil {
 			return &QueryResult{
 				Err: err,
 			}
 		}
+		if dryRun(ctx, q) {
+			return &QueryResult{Res: dryRunResult{}}
+		}
 		res, err := sess.execContext(ctx, q.SQL, q.Args...)
 		return &QueryResult{Err: err, Res: res}
 	}
-	ms := c.ms
-	for i := len(ms) - 1; i >= 0; i-- {
-		handler = ms[i](handler)
-	}
-	qr := handler(ctx, qc)
+	qr := invoke(ctx, c, sess, qc, handler)
 	var res sql.Result
 	if qr.Res != nil {
 		res = qr.Res.(sql.Result)
@@ -7,6 +7,7 @@ import (
 	"context"
 	"database/sql"
 	"database/sql/driver"
+	"errors"
 	"log"
 	"time"
 )
@@ -14,6 +15,10 @@ import (
 type DB struct {
 	db *sql.DB
 	core
+	// stmts is nil unless DBWithStmtCache is used
+	stmts *stmtCache
+	// health is nil unless DBWithHealthCheck is used
+	health *healthChecker
 }
 
 type DBOption func(*DB)
@@ -22,10 +27,10 @@ type DBOption func(*DB)
 // Only for testing
 func (db *DB) Wait() error {
 	err := db.db.Ping()
-	for err == driver.ErrBadConn {
+	for errors.Is(err, driver.ErrBadConn) {
 		log.Printf("等待数据库启动...")
-		err = db.db.Ping()
 		time.Sleep(time.Second)
+		err = db.db.Ping()
 	}
 	return err
 }
@@ -51,6 +56,9 @@ func OpenDB(db *sql.DB, opts ...DBOption) (*DB, error) {
 	for _, opt := range opts {
 		opt(res)
 	}
+	if res.health != nil {
+		go res.health.run()
+	}
 	return res, nil
 
 }
@@ -67,6 +75,79 @@ func DBWithRegistry(r model.Registry) DBOption {
 	}
 }
 
+// DBWithStmtCache caches at most size prepared statements keyed by SQL text,
+// the least recently used one is closed when the cache is full.
+// size <= 0 disables the cache
+func DBWithStmtCache(size int) DBOption {
+	return func(db *DB) {
+		if size <= 0 {
+			return
+		}
+		// the error is impossible since size > 0
+		db.stmts, _ = newStmtCache(db.db, size)
+	}
+}
+
+// DBWithShapeCache caches at most size SQL by the shape of the query,
+// so that the same shape only needs to collect the args to build the query.
+// The cache is cleared when it is full. size <= 0 disables the cache
+func DBWithShapeCache(size int) DBOption {
+	return func(db *DB) {
+		if size <= 0 {
+			return
+		}
+		db.shapes = newShapeCache(size)
+	}
+}
+
+// DBWithMiddlewares appends ms to the middlewares,
+// they are invoked in the order they are added
+func DBWithMiddlewares(ms ...Middleware) DBOption {
+	return func(db *DB) {
+		db.ms = append(db.ms, ms...)
+	}
+}
+
+// DBWithMaxOpenConns sets the maximum number of open connections, n <= 0 means unlimited
+func DBWithMaxOpenConns(n int) DBOption {
+	return func(db *DB) {
+		db.db.SetMaxOpenConns(n)
+	}
+}
+
+// DBWithMaxIdleConns sets the maximum number of idle connections, n <= 0 means no idle connections are kept
+func DBWithMaxIdleConns(n int) DBOption {
+	return func(db *DB) {
+		db.db.SetMaxIdleConns(n)
+	}
+}
+
+// DBWithConnMaxLifetime closes the connections which have been open for d, d <= 0 means forever
+func DBWithConnMaxLifetime(d time.Duration) DBOption {
+	return func(db *DB) {
+		db.db.SetConnMaxLifetime(d)
+	}
+}
+
+// DBWithConnMaxIdleTime closes the connections which have been idle for d, d <= 0 means forever
+func DBWithConnMaxIdleTime(d time.Duration) DBOption {
+	return func(db *DB) {
+		db.db.SetConnMaxIdleTime(d)
+	}
+}
+
+// DBWithHealthCheck pings the database every interval in background, see DB.Health.
+// After a failed ping, the interval is doubled each time until maxBackoff.
+// interval <= 0 disables the health check
+func DBWithHealthCheck(interval time.Duration, maxBackoff time.Duration) DBOption {
+	return func(db *DB) {
+		if interval <= 0 {
+			return
+		}
+		db.health = newHealthChecker(db.db, interval, maxBackoff)
+	}
+}
+
 func DBUseReflectValuer() DBOption {
 	return func(db *DB) {
 		db.valCreator = valuer.NewReflectValue
@@ -78,7 +159,7 @@ func (db *DB) BeginTx(ctx context.Context, opts *sql.TxOptions) (*Tx, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &Tx{tx: tx}, nil
+	return &Tx{tx: tx, db: db}, nil
 }
 
 type txKey struct {
@@ -123,17 +204,48 @@ func (db *DB) DoTx(ctx context.Context, fn func(ctx context.Context, tx *Tx) err
 }
 
 func (db *DB) queryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	if db.stmts != nil {
+		return db.stmts.queryContext(ctx, query, args...)
+	}
 	return db.db.QueryContext(ctx, query, args...)
 }
 
 func (db *DB) execContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	if db.stmts != nil {
+		return db.stmts.execContext(ctx, query, args...)
+	}
 	return db.db.ExecContext(ctx, query, args...)
 }
 func (db *DB) getCore() core {
 	return db.core
 }
 
+// Health returns the error of the last ping of the health checker.
+// It is always nil if DBWithHealthCheck is not used
+func (db *DB) Health() error {
+	if db.health == nil {
+		return nil
+	}
+	return db.health.health()
+}
+
+// Registry returns the registry of the models
+func (db *DB) Registry() model.Registry {
+	return db.r
+}
+
+// Stats returns the statistics of the connection pool
+func (db *DB) Stats() sql.DBStats {
+	return db.db.Stats()
+}
+
 func (db *DB) Close() error {
+	if db.health != nil {
+		db.health.close()
+	}
+	if db.stmts != nil {
+		db.stmts.close()
+	}
 	return db.db.Close()
 }
 
@@ -1,8 +1,7 @@
 package orm
 
 import (
-	"WebFrame/orm/internal/errs"
-	"reflect"
+	"context"
 )
 
 type Deleter[T any] struct {
@@ -19,19 +18,21 @@ func (d *Deleter[T]) From(tbl string) *Deleter[T] {
 }
 
 func (d *Deleter[T]) Build() (*Query, error) {
+	return d.buildQuery(d.build)
+}
+
+func (d *Deleter[T]) build() error {
 	var (
 		t   T
 		err error
 	)
 	d.model, err = d.r.Get(&t)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	d.sb.WriteString("DELETE FROM ")
 	if d.table == "" {
-		var t T
-
-		d.quote(reflect.TypeOf(t).Name())
+		d.quote(d.model.TableName)
 	} else {
 		d.sb.WriteString(d.table)
 	}
@@ -42,60 +43,11 @@ func (d *Deleter[T]) Build() (*Query, error) {
 			p = p.And(d.where[i])
 		}
 		if er := d.buildExpression(p); er != nil {
-			return nil, er
+			return er
 		}
 
 	}
-	d.sb.WriteByte(';')
-	return &Query{
-		SQL:  d.sb.String(),
-		Args: d.args,
-	}, nil
-}
-
-func (d *Deleter[T]) buildExpression(e Expression) error {
-	if e == nil {
-		return nil
-	}
-	switch exp := e.(type) {
-	case Column:
-		fd, ok := d.model.FieldMap[exp.name]
-		if !ok {
-			return errs.NewErrUnknownField(exp.name)
-		}
-		d.quote(fd.ColName)
-	case value:
-		d.sb.WriteByte('?')
-		d.args = append(d.args, exp.val)
-	case Predicate:
-		_, lp := exp.left.(Predicate)
-		if lp {
-			d.sb.WriteByte('(')
-		}
-		if err := d.buildExpression(exp.left); err != nil {
-			return err
-		}
-		if lp {
-			d.sb.WriteByte(')')
-		}
-		d.sb.WriteString(" ")
-		d.sb.WriteString(exp.op.String())
-		d.sb.WriteString(" ")
-		_, rp := exp.right.(Predicate)
-		if rp {
-			d.sb.WriteByte('(')
-		}
-		if err := d.buildExpression(exp.right); err != nil {
-			return err
-		}
-		if rp {
-			d.sb.WriteByte(')')
-		}
-	default:
-		return errs.NewErrUnsupportedExpressionType(exp)
-	}
-	return nil
-
+	return d.sb.WriteByte(';')
 }
 
 func (d *Deleter[T]) Where(preds ...Predicate) *Deleter[T] {
@@ -103,6 +55,23 @@ func (d *Deleter[T]) Where(preds ...Predicate) *Deleter[T] {
 	return d
 }
 
+// scoped returns a copy of d narrowed by ps
+func (d *Deleter[T]) scoped(ps ...Predicate) QueryBuilder {
+	cp := *d
+	cp.builder = d.builder.clone()
+	// do not modify the slice passed by the user
+	cp.where = append(d.where[:len(d.where):len(d.where)], ps...)
+	return &cp
+}
+
+func (d *Deleter[T]) Exec(ctx context.Context) Result {
+	return exec(ctx, d.sess, d.core, &QueryContext{
+		Builder: d,
+		Type:    "DELETE",
+		Model:   modelOf[T](d.core),
+	})
+}
+
 func NewDeleter[T any](sess session) *Deleter[T] {
 	c := sess.getCore()
 	return &Deleter[T]{
@@ -8,13 +8,25 @@ var (
 )
 
 type Dialect interface {
+	// Name returns the name of the database,
+	// it follows the values of db.system in OpenTelemetry
+	Name() string
 	quoter() byte
 	buildUpsert(b *builder, odk *Upsert) error
+	// buildLock builds the locking clause of SELECT,
+	// it returns error if the database does not support it
+	buildLock(b *builder, l rowLock) error
+	// explain returns the prefix which makes a query return its plan
+	explain() string
 }
 
 type standardSQL struct {
 }
 
+func (s standardSQL) Name() string {
+	return "other_sql"
+}
+
 func (s standardSQL) quoter() byte {
 	//TODO implement me
 	panic("implement me")
@@ -25,10 +37,28 @@ func (s standardSQL) buildUpsert(b *builder, odk *Upsert) error {
 	panic("implement me")
 }
 
+func (s standardSQL) buildLock(b *builder, l rowLock) error {
+	b.sb.WriteByte(' ')
+	b.sb.WriteString(l.mode)
+	if l.option != "" {
+		b.sb.WriteByte(' ')
+		b.sb.WriteString(l.option)
+	}
+	return nil
+}
+
+func (s standardSQL) explain() string {
+	return "EXPLAIN "
+}
+
 type mysqlDialect struct {
 	standardSQL
 }
 
+func (m *mysqlDialect) Name() string {
+	return "mysql"
+}
+
 func (m *mysqlDialect) quoter() byte {
 	return '`'
 }
@@ -55,7 +85,9 @@ func (m *mysqlDialect) buildUpsert(b *builder, odk *Upsert) error {
 				return err
 			}
 			b.sb.WriteString("=")
-			return b.buildExpression(assign.val)
+			if err = b.buildExpression(assign.val); err != nil {
+				return err
+			}
 		default:
 			return errs.NewErrUnsupportedAssignableType(a)
 		}
@@ -67,10 +99,22 @@ type sqlite3Dialect struct {
 	standardSQL
 }
 
+func (m *sqlite3Dialect) Name() string {
+	return "sqlite"
+}
+
 func (m *sqlite3Dialect) quoter() byte {
 	return '`'
 }
 
+func (m *sqlite3Dialect) explain() string {
+	return "EXPLAIN QUERY PLAN "
+}
+
+func (m *sqlite3Dialect) buildLock(b *builder, l rowLock) error {
+	return errs.NewErrUnsupportedLock("sqlite3", l.mode)
+}
+
 func (m *sqlite3Dialect) buildUpsert(b *builder, odk *Upsert) error {
 	b.sb.WriteString(" ON CONFLICT(")
 	for idx, col := range odk.conflictColumns {
@@ -102,7 +146,9 @@ func (m *sqlite3Dialect) buildUpsert(b *builder, odk *Upsert) error {
 				return err
 			}
 			b.sb.WriteString("=")
-			return b.buildExpression(assign.val)
+			if err = b.buildExpression(assign.val); err != nil {
+				return err
+			}
 		default:
 			return errs.NewErrUnsupportedAssignableType(a)
 		}
@@ -0,0 +1,69 @@
+package orm
+
+import (
+	"WebFrame/orm/internal/errs"
+	"context"
+	"sync"
+)
+
+// ErrDryRun is returned by the queries reading data under dry-run,
+// and by LastInsertId and RowsAffected of Result, since there is no data.
+// Use errors.Is to check it
+var ErrDryRun = errs.ErrDryRun
+
+type dryRunKey struct{}
+
+// DryRunRecorder collects the queries built under a dry-run context
+type DryRunRecorder struct {
+	mu      sync.Mutex
+	queries []*Query
+}
+
+// DryRun returns a context under which the queries are built and go through
+// the middlewares, but are not sent to the database.
+// The built queries are collected by the returned DryRunRecorder.
+// Under dry-run, the queries reading data such as Get and GetMulti return ErrDryRun,
+// and Exec succeeds but the methods of its Result return ErrDryRun
+func DryRun(ctx context.Context) (context.Context, *DryRunRecorder) {
+	rec := &DryRunRecorder{}
+	return context.WithValue(ctx, dryRunKey{}, rec), rec
+}
+
+// Queries returns the queries in the order they are built
+func (r *DryRunRecorder) Queries() []*Query {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	res := make([]*Query, len(r.queries))
+	copy(res, r.queries)
+	return res
+}
+
+// IsDryRun reports whether ctx is created by DryRun,
+// middlewares can use it to skip their side effects
+func IsDryRun(ctx context.Context) bool {
+	_, ok := ctx.Value(dryRunKey{}).(*DryRunRecorder)
+	return ok
+}
+
+// dryRun records q and reports whether ctx is a dry-run context
+func dryRun(ctx context.Context, q *Query) bool {
+	rec, ok := ctx.Value(dryRunKey{}).(*DryRunRecorder)
+	if !ok {
+		return false
+	}
+	rec.mu.Lock()
+	rec.queries = append(rec.queries, q)
+	rec.mu.Unlock()
+	return true
+}
+
+// dryRunResult is the sql.Result of Exec under dry-run
+type dryRunResult struct{}
+
+func (dryRunResult) LastInsertId() (int64, error) {
+	return 0, errs.ErrDryRun
+}
+
+func (dryRunResult) RowsAffected() (int64, error) {
+	return 0, errs.ErrDryRun
+}
@@ -0,0 +1,89 @@
+package orm
+
+import (
+	"context"
+	"database/sql"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestDryRun(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = mockDB.Close() }()
+	var types []string
+	db, err := OpenDB(mockDB, DBWithMiddlewares(func(next HandleFunc) HandleFunc {
+		return func(ctx context.Context, qc *QueryContext) *QueryResult {
+			types = append(types, qc.Type)
+			return next(ctx, qc)
+		}
+	}))
+	require.NoError(t, err)
+
+	ctx, rec := DryRun(context.Background())
+	tm, err := NewSelector[TestModel](db).Where(C("Id").EQ(1)).Get(ctx)
+	assert.Equal(t, ErrDryRun, err)
+	assert.Nil(t, tm)
+	tms, err := NewSelector[TestModel](db).GetMulti(ctx)
+	assert.Equal(t, ErrDryRun, err)
+	assert.Nil(t, tms)
+	res := NewInserter[TestModel](db).Values(&TestModel{Id: 1}).Exec(ctx)
+	require.NoError(t, res.Err())
+	_, err = res.LastInsertId()
+	assert.Equal(t, ErrDryRun, err)
+	res = NewDeleter[TestModel](db).Where(C("Id").EQ(1)).Exec(ctx)
+	require.NoError(t, res.Err())
+	_, err = res.RowsAffected()
+	assert.Equal(t, ErrDryRun, err)
+	_, err = NewRepository[TestModel](db).Count(ctx)
+	assert.Equal(t, ErrDryRun, err)
+	// a dry run is not mistaken for an existing row
+	exists, err := NewRepository[TestModel](db).Exists(ctx, C("Id").EQ(1))
+	assert.Equal(t, ErrDryRun, err)
+	assert.False(t, exists)
+
+	assert.Equal(t, []string{"SELECT", "SELECT", "INSERT", "DELETE", "SELECT", "SELECT"}, types)
+	assert.Equal(t, []*Query{
+		{SQL: "SELECT * FROM `test_model` WHERE `id` = ?;", Args: []any{1}},
+		{SQL: "SELECT * FROM `test_model`;"},
+		{
+			SQL:  "INSERT INTO `test_model`(`id`, `first_name`, `age`, `last_name`) VALUES(?,?,?,?);",
+			Args: []any{int64(1), "", int8(0), (*sql.NullString)(nil)},
+		},
+		{SQL: "DELETE FROM `test_model` WHERE `id` = ?;", Args: []any{1}},
+		{SQL: "SELECT COUNT(`id`) FROM `test_model`;"},
+		{SQL: "SELECT 1 FROM `test_model` WHERE `id` = ? LIMIT ?;", Args: []any{1, 1}},
+	}, rec.Queries())
+	// nothing is sent to the database
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSelector_Explain(t *testing.T) {
+	db := testModelDB(t, "repository")
+	var types []string
+	db.ms = []Middleware{func(next HandleFunc) HandleFunc {
+		return func(ctx context.Context, qc *QueryContext) *QueryResult {
+			types = append(types, qc.Type)
+			return next(ctx, qc)
+		}
+	}}
+	rows, err := NewSelector[TestModel](db).Where(C("Id").EQ(1)).Explain(context.Background())
+	require.NoError(t, err)
+	require.NotEmpty(t, rows)
+	assert.Contains(t, rows[0].Detail, "test_model")
+	assert.Equal(t, rows[0].Detail, rows[0].Columns["detail"])
+	assert.Equal(t, []string{"EXPLAIN"}, types)
+
+	ctx, rec := DryRun(context.Background())
+	rows, err = NewSelector[TestModel](db).Where(C("Id").EQ(1)).Explain(ctx)
+	assert.Equal(t, ErrDryRun, err)
+	assert.Nil(t, rows)
+	assert.Equal(t, []*Query{
+		{SQL: "EXPLAIN QUERY PLAN SELECT * FROM `test_model` WHERE `id` = ?;", Args: []any{1}},
+	}, rec.Queries())
+
+	_, err = NewSelector[TestModel](db).Where(C("Unknown").EQ(1)).Explain(context.Background())
+	assert.Error(t, err)
+}
@@ -0,0 +1,138 @@
+package orm
+
+import (
+	"WebFrame/orm/internal/errs"
+	"context"
+	"database/sql"
+	"strconv"
+	"strings"
+)
+
+// PlanRow is one row of the query plan returned by Selector.Explain.
+// The common fields are filled from the well-known columns of the dialect,
+// all the columns can be found in Columns
+type PlanRow struct {
+	ID     int64
+	Parent int64
+	Table  string
+	// Type is the access type, such as ALL, ref, in MySQL
+	Type string
+	Key  string
+	// Rows is the estimated number of rows to be examined
+	Rows int64
+	// Detail is Extra in MySQL and detail in SQLite
+	Detail string
+	// Columns contains all the columns of the row, NULL is an empty string
+	Columns map[string]string
+}
+
+// explainBuilder prepends the EXPLAIN clause of the dialect to the query
+type explainBuilder struct {
+	QueryBuilder
+	prefix string
+}
+
+func (e explainBuilder) Build() (*Query, error) {
+	q, err := e.QueryBuilder.Build()
+	if err != nil {
+		return nil, err
+	}
+	return &Query{
+		SQL:  e.prefix + q.SQL,
+		Args: q.Args,
+	}, nil
+}
+
+func (e explainBuilder) scoped(ps ...Predicate) QueryBuilder {
+	if s, ok := e.QueryBuilder.(scopable); ok {
+		e.QueryBuilder = s.scoped(ps...)
+	}
+	return e
+}
+
+// Explain returns the plan of the query, it goes through the middlewares
+// with Type "EXPLAIN"
+func (s *Selector[T]) Explain(ctx context.Context) ([]PlanRow, error) {
+	qc := &QueryContext{
+		Builder: explainBuilder{QueryBuilder: s, prefix: s.dialect.explain()},
+		Type:    "EXPLAIN",
+		Model:   modelOf[T](s.core),
+	}
+	var handler HandleFunc = func(ctx context.Context, qc *QueryContext) *QueryResult {
+		return explainHandler(ctx, s.sess, qc)
+	}
+	res := invoke(ctx, s.core, s.sess, qc, handler)
+	if res.Res != nil {
+		return res.Res.([]PlanRow), res.Err
+	}
+	return nil, res.Err
+}
+
+func explainHandler(ctx context.Context, sess session, qc *QueryContext) *QueryResult {
+	q, err := qc.Query()
+	if err != nil {
+		return &QueryResult{
+			Err: err,
+		}
+	}
+	if dryRun(ctx, q) {
+		return &QueryResult{
+			Err: errs.ErrDryRun,
+		}
+	}
+	rows, err := sess.queryContext(ctx, q.SQL, q.Args...)
+	if err != nil {
+		return &QueryResult{
+			Err: err,
+		}
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	cols, err := rows.Columns()
+	if err != nil {
+		return &QueryResult{
+			Err: err,
+		}
+	}
+	res := make([]PlanRow, 0, 4)
+	for rows.Next() {
+		vals := make([]sql.NullString, len(cols))
+		ptrs := make([]any, len(cols))
+		for i := range vals {
+			ptrs[i] = &vals[i]
+		}
+		if err = rows.Scan(ptrs...); err != nil {
+			return &QueryResult{
+				Err: err,
+			}
+		}
+		row := PlanRow{Columns: make(map[string]string, len(cols))}
+		for i, col := range cols {
+			val := vals[i].String
+			row.Columns[col] = val
+			switch strings.ToLower(col) {
+			case "id":
+				row.ID, _ = strconv.ParseInt(val, 10, 64)
+			case "parent":
+				row.Parent, _ = strconv.ParseInt(val, 10, 64)
+			case "table":
+				row.Table = val
+			case "type":
+				row.Type = val
+			case "key":
+				row.Key = val
+			case "rows":
+				row.Rows, _ = strconv.ParseInt(val, 10, 64)
+			case "detail", "extra":
+				row.Detail = val
+			}
+		}
+		res = append(res, row)
+	}
+	return &QueryResult{
+		Res: res,
+		Err: rows.Err(),
+	}
+}
@@ -21,3 +21,223 @@ func Raw(expr string, args ...interface{}) RawExpr {
 		args: args,
 	}
 }
+
+// binaryExpr is the common structure of Predicate and MathExpr
+type binaryExpr struct {
+	left  Expression
+	op    op
+	right Expression
+}
+
+func (binaryExpr) expr() {}
+
+// MathExpr represents an arithmetic expression, such as `age` + 1
+type MathExpr binaryExpr
+
+func (m MathExpr) expr() {}
+
+func (m MathExpr) selectable() {}
+
+func (m MathExpr) Add(val any) MathExpr {
+	return MathExpr{
+		left:  m,
+		op:    opAdd,
+		right: exprOf(val),
+	}
+}
+
+func (m MathExpr) Multiply(val any) MathExpr {
+	return MathExpr{
+		left:  m,
+		op:    opMulti,
+		right: exprOf(val),
+	}
+}
+
+// As is used in SELECT, MathExpr itself does not carry an alias
+func (m MathExpr) As(alias string) Selectable {
+	return aliasExpr{
+		expr:  m,
+		alias: alias,
+	}
+}
+
+func (m MathExpr) EQ(arg any) Predicate {
+	return Predicate{
+		left:  m,
+		op:    opEQ,
+		right: exprOf(arg),
+	}
+}
+
+func (m MathExpr) LT(arg any) Predicate {
+	return Predicate{
+		left:  m,
+		op:    opLT,
+		right: exprOf(arg),
+	}
+}
+
+func (m MathExpr) GT(arg any) Predicate {
+	return Predicate{
+		left:  m,
+		op:    opGT,
+		right: exprOf(arg),
+	}
+}
+
+// aliasExpr binds an alias to an expression which can not hold it
+type aliasExpr struct {
+	expr  Expression
+	alias string
+}
+
+func (a aliasExpr) selectable() {}
+
+// CaseExpr represents CASE WHEN ... THEN ... ELSE ... END
+type CaseExpr struct {
+	whens []caseWhen
+	// nil means no ELSE
+	els   Expression
+	alias string
+}
+
+type caseWhen struct {
+	cond Predicate
+	then Expression
+}
+
+func (c CaseExpr) expr() {}
+
+func (c CaseExpr) selectable() {}
+
+// Case creates a CaseExpr, example Case().When(C("Age").GT(18), "adult").Else("child")
+func Case() CaseExpr {
+	return CaseExpr{}
+}
+
+func (c CaseExpr) When(cond Predicate, then any) CaseExpr {
+	whens := make([]caseWhen, len(c.whens), len(c.whens)+1)
+	copy(whens, c.whens)
+	c.whens = append(whens, caseWhen{cond: cond, then: exprOf(then)})
+	return c
+}
+
+func (c CaseExpr) Else(val any) CaseExpr {
+	c.els = exprOf(val)
+	return c
+}
+
+func (c CaseExpr) As(alias string) CaseExpr {
+	c.alias = alias
+	return c
+}
+
+func (c CaseExpr) EQ(arg any) Predicate {
+	return Predicate{
+		left:  c,
+		op:    opEQ,
+		right: exprOf(arg),
+	}
+}
+
+// FuncExpr represents a SQL function call, such as COALESCE(`last_name`, ?)
+// The arguments which are not Expression will be passed as parameters
+type FuncExpr struct {
+	fn    string
+	args  []Expression
+	alias string
+}
+
+func (f FuncExpr) expr() {}
+
+func (f FuncExpr) selectable() {}
+
+// Func creates a FuncExpr, use C to refer to columns in args
+func Func(fn string, args ...any) FuncExpr {
+	exprs := make([]Expression, 0, len(args))
+	for _, arg := range args {
+		exprs = append(exprs, exprOf(arg))
+	}
+	return FuncExpr{
+		fn:   fn,
+		args: exprs,
+	}
+}
+
+func Coalesce(args ...any) FuncExpr {
+	return Func("COALESCE", args...)
+}
+
+func RowNumber() FuncExpr {
+	return Func("ROW_NUMBER")
+}
+
+func Rank() FuncExpr {
+	return Func("RANK")
+}
+
+func DenseRank() FuncExpr {
+	return Func("DENSE_RANK")
+}
+
+func (f FuncExpr) As(alias string) FuncExpr {
+	f.alias = alias
+	return f
+}
+
+// Over turns the function into a window function
+func (f FuncExpr) Over() WindowExpr {
+	return WindowExpr{fn: f}
+}
+
+func (f FuncExpr) EQ(arg any) Predicate {
+	return Predicate{
+		left:  f,
+		op:    opEQ,
+		right: exprOf(arg),
+	}
+}
+
+func (f FuncExpr) LT(arg any) Predicate {
+	return Predicate{
+		left:  f,
+		op:    opLT,
+		right: exprOf(arg),
+	}
+}
+
+func (f FuncExpr) GT(arg any) Predicate {
+	return Predicate{
+		left:  f,
+		op:    opGT,
+		right: exprOf(arg),
+	}
+}
+
+// WindowExpr represents fn OVER (PARTITION BY ... ORDER BY ...)
+type WindowExpr struct {
+	fn          Expression
+	partitionBy []Column
+	orderBy     []OrderBy
+	alias       string
+}
+
+func (w WindowExpr) expr() {}
+
+func (w WindowExpr) selectable() {}
+
+func (w WindowExpr) PartitionBy(cols ...Column) WindowExpr {
+	w.partitionBy = cols
+	return w
+}
+
+func (w WindowExpr) OrderBy(obs ...OrderBy) WindowExpr {
+	w.orderBy = obs
+	return w
+}
+
+func (w WindowExpr) As(alias string) WindowExpr {
+	w.alias = alias
+	return w
+}
@@ -0,0 +1,126 @@
+package orm
+
+import (
+	"WebFrame/orm/internal/errs"
+	"database/sql"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestExpression_Build(t *testing.T) {
+	db := memoryDB(t)
+	testCases := []struct {
+		name      string
+		q         QueryBuilder
+		wantQuery *Query
+		wantErr   error
+	}{
+		{
+			name: "math in select",
+			q:    NewSelector[TestModel](db).Select(C("Age").Add(1).Multiply(2).As("double_age")),
+			wantQuery: &Query{
+				SQL:  "SELECT (`age` + ?) * ? AS `double_age` FROM `test_model`;",
+				Args: []any{1, 2},
+			},
+		},
+		{
+			name: "math in where",
+			q:    NewSelector[TestModel](db).Where(C("Age").Add(1).GT(18)),
+			wantQuery: &Query{
+				SQL:  "SELECT * FROM `test_model` WHERE (`age` + ?) > ?;",
+				Args: []any{1, 18},
+			},
+		},
+		{
+			name: "math with column",
+			q:    NewSelector[TestModel](db).Where(C("Age").Multiply(C("Id")).EQ(C("Age"))),
+			wantQuery: &Query{
+				SQL: "SELECT * FROM `test_model` WHERE (`age` * `id`) = `age`;",
+			},
+		},
+		{
+			name: "case",
+			q: NewSelector[TestModel](db).Select(C("Id"),
+				Case().When(C("Age").GT(60), "old").When(C("Age").GT(18), "adult").Else("child").As("grp")),
+			wantQuery: &Query{
+				SQL: "SELECT `id`,CASE WHEN `age` > ? THEN ? WHEN `age` > ? THEN ? ELSE ? END AS `grp` " +
+					"FROM `test_model`;",
+				Args: []any{60, "old", 18, "adult", "child"},
+			},
+		},
+		{
+			name: "case in where",
+			q:    NewSelector[TestModel](db).Where(Case().When(C("Age").GT(18), 1).Else(0).EQ(1)),
+			wantQuery: &Query{
+				SQL:  "SELECT * FROM `test_model` WHERE CASE WHEN `age` > ? THEN ? ELSE ? END = ?;",
+				Args: []any{18, 1, 0, 1},
+			},
+		},
+		{
+			name: "coalesce",
+			q:    NewSelector[TestModel](db).Select(Coalesce(C("LastName"), C("FirstName"), "unknown").As("name")),
+			wantQuery: &Query{
+				SQL:  "SELECT COALESCE(`last_name`,`first_name`,?) AS `name` FROM `test_model`;",
+				Args: []any{"unknown"},
+			},
+		},
+		{
+			name: "function in having",
+			q:    NewSelector[TestModel](db).GroupBy(C("Age")).Having(Func("LENGTH", C("FirstName")).GT(3)),
+			wantQuery: &Query{
+				SQL:  "SELECT * FROM `test_model` GROUP BY `age` HAVING LENGTH(`first_name`) > ?;",
+				Args: []any{3},
+			},
+		},
+		{
+			name: "row number",
+			q: NewSelector[TestModel](db).Select(C("Id"),
+				RowNumber().Over().PartitionBy(C("Age")).OrderBy(Desc("Id"), Asc("FirstName")).As("rn")),
+			wantQuery: &Query{
+				SQL: "SELECT `id`,ROW_NUMBER() OVER (PARTITION BY `age` ORDER BY `id` DESC,`first_name` ASC) AS `rn` " +
+					"FROM `test_model`;",
+			},
+		},
+		{
+			name: "aggregate over",
+			q:    NewSelector[TestModel](db).Select(Sum("Age").Over().OrderBy(Asc("Id"))),
+			wantQuery: &Query{
+				SQL: "SELECT SUM(`age`) OVER (ORDER BY `id` ASC) FROM `test_model`;",
+			},
+		},
+		{
+			name:    "window invalid column",
+			q:       NewSelector[TestModel](db).Select(RowNumber().Over().PartitionBy(C("Invalid"))),
+			wantErr: errs.NewErrUnknownField("Invalid"),
+		},
+		{
+			name: "assign math",
+			q: NewInserter[TestModel](db).Values(&TestModel{Id: 1, FirstName: "Deng", Age: 18}).
+				Columns("Id", "FirstName", "Age").
+				OnDuplicateKey().Update(Assign("Age", C("Age").Add(1)), C("FirstName")),
+			wantQuery: &Query{
+				SQL: "INSERT INTO `test_model`(`id`, `first_name`, `age`) VALUES(?,?,?) " +
+					"ON DUPLICATE KEY UPDATE `age`=`age` + ?,`first_name`=VALUES(`first_name`);",
+				Args: []any{int64(1), "Deng", int8(18), 1},
+			},
+		},
+		{
+			name: "delete with function",
+			q:    NewDeleter[TestModel](db).Where(Coalesce(C("LastName"), "").EQ(sql.NullString{})),
+			wantQuery: &Query{
+				SQL:  "DELETE FROM `test_model` WHERE COALESCE(`last_name`,?) = ?;",
+				Args: []any{"", sql.NullString{}},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			query, err := tc.q.Build()
+			assert.Equal(t, tc.wantErr, err)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, tc.wantQuery, query)
+		})
+	}
+}
@@ -0,0 +1,263 @@
+package orm
+
+import (
+	"WebFrame/orm/internal/errs"
+	"WebFrame/orm/model"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	filterKeySort     = "sort"
+	filterKeyPage     = "page"
+	filterKeyPageSize = "page_size"
+)
+
+// filterOps maps the suffix of the key to the predicate
+var filterOps = map[string]func(c Column, vals []any) Predicate{
+	"eq":  func(c Column, vals []any) Predicate { return c.EQ(vals[0]) },
+	"ne":  func(c Column, vals []any) Predicate { return c.NEQ(vals[0]) },
+	"gt":  func(c Column, vals []any) Predicate { return c.GT(vals[0]) },
+	"gte": func(c Column, vals []any) Predicate { return c.GTE(vals[0]) },
+	"lt":  func(c Column, vals []any) Predicate { return c.LT(vals[0]) },
+	"lte": func(c Column, vals []any) Predicate { return c.LTE(vals[0]) },
+	"in":  func(c Column, vals []any) Predicate { return c.In(vals...) },
+	"like": func(c Column, vals []any) Predicate {
+		return Predicate{
+			left:  c,
+			op:    opLike,
+			right: Raw("? ESCAPE '"+likeEscapeChar+"'", "%"+likeEscaper.Replace(vals[0].(string))+"%"),
+		}
+	},
+}
+
+// likeEscapeChar is not special in the string literals of the databases, unlike backslash in MySQL
+const likeEscapeChar = "!"
+
+// likeEscaper escapes the wildcards, so that the value of _like is matched literally
+var likeEscaper = strings.NewReplacer(
+	likeEscapeChar, likeEscapeChar+likeEscapeChar,
+	"%", likeEscapeChar+"%",
+	"_", likeEscapeChar+"_",
+)
+
+// Filter translates the query string of HTTP, such as
+//
+//	?age_gt=18&name_like=bob&status_in=1,2&sort=-id,name&page=2&page_size=20
+//
+// into the predicates, ORDER BY, LIMIT and OFFSET of a Selector.
+// The keys are the column names, optionally followed by one of the operators
+// _eq, _ne, _gt, _gte, _lt, _lte, _like and _in, and _eq is the default.
+// The values of _in are separated by comma, and _like matches the value literally as a substring.
+// A column can be used only if its field is allowed, all the fields are allowed by default.
+// The keys with an operator, such as nmae_like, must refer to columns,
+// while the bare keys which are not columns, such as token, are ignored, see also Ignore
+type Filter[T any] struct {
+	allowed         map[string]bool
+	ignored         map[string]bool
+	defaultPageSize int
+	maxPageSize     int
+	maxIn           int
+}
+
+func NewFilter[T any]() *Filter[T] {
+	return &Filter[T]{
+		defaultPageSize: 20,
+		maxPageSize:     100,
+		maxIn:           100,
+	}
+}
+
+// Allow restricts the fields which can be filtered and sorted by
+func (f *Filter[T]) Allow(fields ...string) *Filter[T] {
+	f.allowed = make(map[string]bool, len(fields))
+	for _, fd := range fields {
+		f.allowed[fd] = true
+	}
+	return f
+}
+
+// Ignore skips the keys which are not filters, such as token_in
+func (f *Filter[T]) Ignore(keys ...string) *Filter[T] {
+	if f.ignored == nil {
+		f.ignored = make(map[string]bool, len(keys))
+	}
+	for _, key := range keys {
+		f.ignored[key] = true
+	}
+	return f
+}
+
+// PageSize sets the page size used when page_size is absent,
+// and the maximum page size the client can ask for
+func (f *Filter[T]) PageSize(defaultSize, maxSize int) *Filter[T] {
+	f.defaultPageSize = defaultSize
+	f.maxPageSize = maxSize
+	return f
+}
+
+// MaxIn sets the max number of the values of _in, n <= 0 means unlimited
+func (f *Filter[T]) MaxIn(n int) *Filter[T] {
+	f.maxIn = n
+	return f
+}
+
+// Apply adds the conditions in vals to s. vals can be url.Values,
+// and the returned error is caused by the input, so it is safe to be returned to the client
+func (f *Filter[T]) Apply(s *Selector[T], vals map[string][]string) (*Selector[T], error) {
+	m, err := s.r.Get(new(T))
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, len(vals))
+	for key := range vals {
+		keys = append(keys, key)
+	}
+	// the order of map is random, sort it to generate the same SQL
+	sort.Strings(keys)
+
+	var ps []Predicate
+	page, size := 1, f.defaultPageSize
+	for _, key := range keys {
+		if len(vals[key]) == 0 {
+			continue
+		}
+		val := vals[key][0]
+		switch key {
+		case filterKeySort:
+			obs, er := f.orderBy(m, val)
+			if er != nil {
+				return nil, er
+			}
+			s = s.OrderBy(obs...)
+		case filterKeyPage:
+			page, err = strconv.Atoi(val)
+			if err != nil || page < 1 {
+				return nil, errs.NewErrInvalidFilterValue(key, val)
+			}
+		case filterKeyPageSize:
+			size, err = strconv.Atoi(val)
+			if err != nil || size < 1 {
+				return nil, errs.NewErrInvalidFilterValue(key, val)
+			}
+		default:
+			for _, v := range vals[key] {
+				p, ok, er := f.predicate(m, key, v)
+				if er != nil {
+					return nil, er
+				}
+				if ok {
+					ps = append(ps, p)
+				}
+			}
+		}
+	}
+	if len(ps) > 0 {
+		s.addWhere(ps...)
+	}
+	if f.maxPageSize > 0 && size > f.maxPageSize {
+		size = f.maxPageSize
+	}
+	if size > 0 {
+		s = s.Limit(size).Offset((page - 1) * size)
+	}
+	return s, nil
+}
+
+func (f *Filter[T]) field(m *model.Model, col string) (*model.Field, bool) {
+	fd, ok := m.ColumnMap[col]
+	if !ok || (f.allowed != nil && !f.allowed[fd.GoName]) {
+		return nil, false
+	}
+	return fd, true
+}
+
+// predicate returns false if key is ignored
+func (f *Filter[T]) predicate(m *model.Model, key string, val string) (Predicate, bool, error) {
+	if f.ignored[key] {
+		return Predicate{}, false, nil
+	}
+	col, op := key, "eq"
+	hasOp := false
+	if idx := strings.LastIndexByte(key, '_'); idx > 0 {
+		if _, ok := filterOps[key[idx+1:]]; ok {
+			col, op, hasOp = key[:idx], key[idx+1:], true
+		}
+	}
+	if _, ok := m.ColumnMap[col]; !ok {
+		// the column may contain an underscore, such as user_id
+		col, op = key, "eq"
+		if _, ok = m.ColumnMap[col]; !ok {
+			if hasOp {
+				// a typo such as nmae_like should not widen the result silently
+				return Predicate{}, false, errs.NewErrUnknownFilterField(key)
+			}
+			return Predicate{}, false, nil
+		}
+	}
+	fd, ok := f.field(m, col)
+	if !ok {
+		return Predicate{}, false, errs.NewErrUnknownFilterField(key)
+	}
+	raws := []string{val}
+	if op == "in" {
+		raws = strings.Split(val, ",")
+		if f.maxIn > 0 && len(raws) > f.maxIn {
+			return Predicate{}, false, errs.NewErrTooManyFilterValues(key, f.maxIn)
+		}
+	}
+	args := make([]any, 0, len(raws))
+	for _, raw := range raws {
+		if op == "like" {
+			args = append(args, raw)
+			continue
+		}
+		arg, err := parseFilterValue(fd.Type, raw)
+		if err != nil {
+			return Predicate{}, false, errs.NewErrInvalidFilterValue(key, val)
+		}
+		args = append(args, arg)
+	}
+	return filterOps[op](C(fd.GoName), args), true, nil
+}
+
+// orderBy parses the comma separated columns, and - means DESC
+func (f *Filter[T]) orderBy(m *model.Model, val string) ([]OrderBy, error) {
+	cols := strings.Split(val, ",")
+	obs := make([]OrderBy, 0, len(cols))
+	for _, col := range cols {
+		desc := strings.HasPrefix(col, "-")
+		fd, ok := f.field(m, strings.TrimPrefix(col, "-"))
+		if !ok {
+			return nil, errs.NewErrUnknownFilterField(col)
+		}
+		if desc {
+			obs = append(obs, Desc(fd.GoName))
+		} else {
+			obs = append(obs, Asc(fd.GoName))
+		}
+	}
+	return obs, nil
+}
+
+// parseFilterValue converts val to the basic kind of typ,
+// the other types such as time.Time are compared as string by the database
+func parseFilterValue(typ reflect.Type, val string) (any, error) {
+	if typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.ParseInt(val, 10, typ.Bits())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.ParseUint(val, 10, typ.Bits())
+	case reflect.Float32, reflect.Float64:
+		return strconv.ParseFloat(val, typ.Bits())
+	case reflect.Bool:
+		return strconv.ParseBool(val)
+	default:
+		return val, nil
+	}
+}
@@ -0,0 +1,163 @@
+package orm
+
+import (
+	"WebFrame/orm/internal/errs"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/url"
+	"testing"
+)
+
+func TestFilter_Apply(t *testing.T) {
+	db := memoryDB(t)
+	testCases := []struct {
+		name      string
+		filter    *Filter[TestModel]
+		query     string
+		wantQuery *Query
+		wantErr   error
+	}{
+		{
+			name:   "default page",
+			filter: NewFilter[TestModel](),
+			wantQuery: &Query{
+				SQL:  "SELECT * FROM `test_model` LIMIT ?;",
+				Args: []any{20},
+			},
+		},
+		{
+			name:   "operators",
+			filter: NewFilter[TestModel](),
+			query:  "age_gt=18&first_name_like=bob&id_in=1,2&last_name=Ming",
+			wantQuery: &Query{
+				SQL: "SELECT * FROM `test_model` WHERE (((`age` > ?) AND (`first_name` LIKE ? ESCAPE '!')) AND (`id` IN (?,?))) " +
+					"AND (`last_name` = ?) LIMIT ?;",
+				Args: []any{int64(18), "%bob%", int64(1), int64(2), "Ming", 20},
+			},
+		},
+		{
+			name:   "like escaped",
+			filter: NewFilter[TestModel](),
+			query:  "first_name_like=" + url.QueryEscape("50%_off!"),
+			wantQuery: &Query{
+				SQL:  "SELECT * FROM `test_model` WHERE `first_name` LIKE ? ESCAPE '!' LIMIT ?;",
+				Args: []any{"%50!%!_off!!%", 20},
+			},
+		},
+		{
+			// 不是字段的参数被忽略
+			name:   "not filter",
+			filter: NewFilter[TestModel](),
+			query:  "token=abc&_=1700000000&age=18",
+			wantQuery: &Query{
+				SQL:  "SELECT * FROM `test_model` WHERE `age` = ? LIMIT ?;",
+				Args: []any{int64(18), 20},
+			},
+		},
+		{
+			name:    "unknown field with operator",
+			filter:  NewFilter[TestModel](),
+			query:   "nmae_like=bob",
+			wantErr: errs.NewErrUnknownFilterField("nmae_like"),
+		},
+		{
+			name:   "ignored",
+			filter: NewFilter[TestModel]().Ignore("token_in"),
+			query:  "token_in=a,b&age_gt=18",
+			wantQuery: &Query{
+				SQL:  "SELECT * FROM `test_model` WHERE `age` > ? LIMIT ?;",
+				Args: []any{int64(18), 20},
+			},
+		},
+		{
+			name:    "too many in",
+			filter:  NewFilter[TestModel]().MaxIn(2),
+			query:   "id_in=1,2,3",
+			wantErr: errs.NewErrTooManyFilterValues("id_in", 2),
+		},
+		{
+			name:   "sort and page",
+			filter: NewFilter[TestModel]().PageSize(10, 50),
+			query:  "sort=-id,age&page=3&page_size=100",
+			wantQuery: &Query{
+				SQL:  "SELECT * FROM `test_model` ORDER BY `id` DESC,`age` ASC LIMIT ? OFFSET ?;",
+				Args: []any{50, 100},
+			},
+		},
+		{
+			name:    "not allowed",
+			filter:  NewFilter[TestModel]().Allow("Age"),
+			query:   "id=1",
+			wantErr: errs.NewErrUnknownFilterField("id"),
+		},
+		{
+			name:    "sort not allowed",
+			filter:  NewFilter[TestModel]().Allow("Age"),
+			query:   "sort=-id",
+			wantErr: errs.NewErrUnknownFilterField("-id"),
+		},
+		{
+			name:    "invalid value",
+			filter:  NewFilter[TestModel](),
+			query:   "age_gte=abc",
+			wantErr: errs.NewErrInvalidFilterValue("age_gte", "abc"),
+		},
+		{
+			name:    "invalid page",
+			filter:  NewFilter[TestModel](),
+			query:   "page=0",
+			wantErr: errs.NewErrInvalidFilterValue("page", "0"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			vals, err := url.ParseQuery(tc.query)
+			assert.NoError(t, err)
+			s, err := tc.filter.Apply(NewSelector[TestModel](db), vals)
+			assert.Equal(t, tc.wantErr, err)
+			if err != nil {
+				return
+			}
+			query, err := s.Build()
+			assert.NoError(t, err)
+			assert.Equal(t, tc.wantQuery, query)
+		})
+	}
+}
+
+func TestFilter_Like(t *testing.T) {
+	db := testModelDB(t, "repository")
+	ctx := context.Background()
+	tx, err := db.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	defer func() { _ = tx.Rollback() }()
+	require.NoError(t, NewInserter[TestModel](tx).Values(
+		&TestModel{Id: 1, FirstName: "50% off"},
+		&TestModel{Id: 2, FirstName: "500 off"},
+		&TestModel{Id: 3, FirstName: "a_b"},
+		&TestModel{Id: 4, FirstName: "acb"},
+	).Exec(ctx).Err())
+
+	testCases := []struct {
+		val     string
+		wantIds []int64
+	}{
+		{val: "50%", wantIds: []int64{1}},
+		{val: "a_b", wantIds: []int64{3}},
+		{val: "off", wantIds: []int64{1, 2}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.val, func(t *testing.T) {
+			s, err := NewFilter[TestModel]().Apply(NewSelector[TestModel](tx), url.Values{"first_name_like": {tc.val}})
+			require.NoError(t, err)
+			tms, err := s.OrderBy(Asc("Id")).GetMulti(ctx)
+			require.NoError(t, err)
+			ids := make([]int64, 0, len(tms))
+			for _, tm := range tms {
+				ids = append(ids, tm.Id)
+			}
+			assert.Equal(t, tc.wantIds, ids)
+		})
+	}
+}
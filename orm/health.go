@@ -0,0 +1,84 @@
+package orm
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+)
+
+// healthChecker pings the database in background.
+// After a failed ping, the delay of the next ping is doubled until maxBackoff,
+// and it is reset to interval as soon as a ping succeeds
+type healthChecker struct {
+	db         *sql.DB
+	interval   time.Duration
+	maxBackoff time.Duration
+
+	mu  sync.RWMutex
+	err error
+
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func newHealthChecker(db *sql.DB, interval, maxBackoff time.Duration) *healthChecker {
+	if maxBackoff < interval {
+		maxBackoff = interval
+	}
+	return &healthChecker{
+		db:         db,
+		interval:   interval,
+		maxBackoff: maxBackoff,
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+}
+
+func (h *healthChecker) run() {
+	defer close(h.done)
+	delay := h.interval
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	for {
+		select {
+		case <-h.stop:
+			return
+		case <-timer.C:
+		}
+		if err := h.ping(); err != nil {
+			delay *= 2
+			if delay > h.maxBackoff {
+				delay = h.maxBackoff
+			}
+		} else {
+			delay = h.interval
+		}
+		timer.Reset(delay)
+	}
+}
+
+func (h *healthChecker) ping() error {
+	ctx, cancel := context.WithTimeout(context.Background(), h.interval)
+	defer cancel()
+	err := h.db.PingContext(ctx)
+	h.mu.Lock()
+	h.err = err
+	h.mu.Unlock()
+	return err
+}
+
+func (h *healthChecker) health() error {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.err
+}
+
+// close stops the background goroutine, it can be called more than once
+func (h *healthChecker) close() {
+	h.closeOnce.Do(func() {
+		close(h.stop)
+	})
+	<-h.done
+}
@@ -0,0 +1,56 @@
+package orm
+
+import (
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestDB_Health(t *testing.T) {
+	mockDB, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	require.NoError(t, err)
+	pingErr := errors.New("ping error")
+	mock.ExpectPing().WillReturnError(pingErr)
+	for i := 0; i < 100; i++ {
+		mock.ExpectPing()
+	}
+	db, err := OpenDB(mockDB, DBWithHealthCheck(time.Millisecond, 4*time.Millisecond))
+	require.NoError(t, err)
+	defer func() {
+		_ = db.Close()
+	}()
+	// 第一次 ping 失败，退避之后恢复
+	assert.Eventually(t, func() bool {
+		return errors.Is(db.Health(), pingErr)
+	}, time.Second, time.Millisecond)
+	assert.Eventually(t, func() bool {
+		return db.Health() == nil
+	}, time.Second, time.Millisecond)
+}
+
+func TestDB_CloseTwice(t *testing.T) {
+	mockDB, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	require.NoError(t, err)
+	mock.MatchExpectationsInOrder(false)
+	for i := 0; i < 100; i++ {
+		mock.ExpectPing()
+	}
+	mock.ExpectClose()
+	db, err := OpenDB(mockDB, DBWithHealthCheck(time.Millisecond, time.Millisecond))
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+	// database/sql allows closing twice, so does DB
+	assert.NotPanics(t, func() {
+		_ = db.Close()
+	})
+}
+
+func TestDB_PoolOptions(t *testing.T) {
+	db := memoryDB(t, DBWithMaxOpenConns(3), DBWithMaxIdleConns(2),
+		DBWithConnMaxLifetime(time.Minute), DBWithConnMaxIdleTime(time.Second))
+	assert.Equal(t, 3, db.Stats().MaxOpenConnections)
+	assert.Nil(t, db.Health())
+}
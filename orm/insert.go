@@ -4,7 +4,8 @@ import (
 	"WebFrame/orm/internal/errs"
 	"WebFrame/orm/model"
 	"context"
-	"fmt"
+	"reflect"
+	"slices"
 )
 
 type UpsertBuilder[T any] struct {
@@ -46,6 +47,7 @@ func NewInserter[T any](sess session) *Inserter[T] {
 		core: c,
 		sess: sess,
 		builder: builder{
+			core:    c,
 			dialect: c.dialect,
 			quoter:  c.dialect.quoter(),
 		},
@@ -71,14 +73,54 @@ func (i *Inserter[T]) Columns(cols ...string) *Inserter[T] {
 	return i
 }
 
+func (i *Inserter[T]) isUpsert() bool {
+	return i.upsert != nil
+}
+
+// withValue returns a copy of i whose values are copied and their field is set to val,
+// and makes sure the field is one of the columns to be inserted
+func (i *Inserter[T]) withValue(field string, val any) (QueryBuilder, error) {
+	m, err := i.r.Get(new(T))
+	if err != nil {
+		return nil, err
+	}
+	fd, ok := m.FieldMap[field]
+	if !ok {
+		return nil, errs.NewErrUnknownField(field)
+	}
+	v := reflect.ValueOf(val)
+	if !v.IsValid() || !v.Type().ConvertibleTo(fd.Type) {
+		return nil, errs.NewErrInvalidFieldValue(field, val)
+	}
+	v = v.Convert(fd.Type)
+	cp := *i
+	cp.builder = i.builder.clone()
+	cp.values = make([]*T, 0, len(i.values))
+	for _, row := range i.values {
+		// do not modify the values passed by the user
+		rowCp := new(T)
+		*rowCp = *row
+		reflect.ValueOf(rowCp).Elem().Field(fd.Index).Set(v)
+		cp.values = append(cp.values, rowCp)
+	}
+	if len(i.columns) > 0 && !slices.Contains(i.columns, field) {
+		// do not modify the slice passed by the user
+		cp.columns = append(i.columns[:len(i.columns):len(i.columns)], field)
+	}
+	return &cp, nil
+}
+
 func (i *Inserter[T]) Build() (*Query, error) {
+	return i.buildQuery(i.build)
+}
+
+func (i *Inserter[T]) build() error {
 	if len(i.values) == 0 {
-		return nil, errs.ErrInsertZeroRow
+		return errs.ErrInsertZeroRow
 	}
-	fmt.Println("i.values", i.values)
 	m, err := i.r.Get(i.values[0])
 	if err != nil {
-		return nil, err
+		return err
 	}
 	i.model = m
 	i.sb.WriteString("INSERT INTO ")
@@ -90,7 +132,7 @@ func (i *Inserter[T]) Build() (*Query, error) {
 		for _, c := range i.columns {
 			field, ok := m.FieldMap[c]
 			if !ok {
-				return nil, errs.NewErrUnknownField(c)
+				return errs.NewErrUnknownField(c)
 			}
 			fields = append(fields, field)
 		}
@@ -118,7 +160,7 @@ func (i *Inserter[T]) Build() (*Query, error) {
 			i.sb.WriteByte('?')
 			fdVal, err := refVal.Field(field.GoName)
 			if err != nil {
-				return nil, err
+				return err
 			}
 			i.addArgs(fdVal)
 		}
@@ -129,14 +171,10 @@ func (i *Inserter[T]) Build() (*Query, error) {
 	if i.upsert != nil {
 		err = i.core.dialect.buildUpsert(&i.builder, i.upsert)
 		if err != nil {
-			return nil, err
+			return err
 		}
 	}
-	i.sb.WriteByte(';')
-	return &Query{
-		SQL:  i.sb.String(),
-		Args: i.args,
-	}, nil
+	return i.sb.WriteByte(';')
 
 }
 
@@ -148,5 +186,6 @@ func (i *Inserter[T]) Exec(ctx context.Context) Result {
 	return exec(ctx, i.sess, i.core, &QueryContext{
 		Builder: i,
 		Type:    "INSERT",
+		Model:   modelOf[T](i.core),
 	})
 }
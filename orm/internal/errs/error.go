@@ -15,6 +15,22 @@ var (
 	ErrNoRows                 = errors.New("orm: no data found")
 	ErrTooManyReturnedColumns = errors.New("orm: too many columns")
 	ErrInsertZeroRow          = errors.New("orm: insert zero row")
+	// ErrLockOutsideTx means FOR UPDATE or FOR SHARE is used without transaction,
+	// the lock would be released as soon as the statement finishes
+	ErrLockOutsideTx = errors.New("orm: row lock can only be used in transaction")
+	// ErrNoUpdatedColumns means Updater.Set is not called
+	ErrNoUpdatedColumns = errors.New("orm: no columns to update")
+	// ErrNoUpdatedValue means a column is assigned without calling Updater.Update
+	ErrNoUpdatedValue = errors.New("orm: no value to update")
+	// ErrDryRun is returned instead of the result under dry-run,
+	// so that it is not mistaken for real data
+	ErrDryRun = errors.New("orm: dry run, no result")
+	// ErrSnapshotWithoutWhere means UPDATE or DELETE without WHERE is snapshotted,
+	// which would read the whole table
+	ErrSnapshotWithoutWhere = errors.New("orm: can not snapshot UPDATE or DELETE without WHERE")
+	// ErrInvalidSetQueryPart means a selector of SetQuery uses ORDER BY, LIMIT, OFFSET or a row lock,
+	// use them on the SetQuery instead
+	ErrInvalidSetQueryPart = errors.New("orm: selector in set query can not use ORDER BY, LIMIT, OFFSET or row lock")
 )
 
 // NewErrUnknownField returns an error representing an unknown field
@@ -32,6 +48,38 @@ func NewErrUnsupportedExpressionType(exp any) error {
 	return fmt.Errorf("orm: unsupported expression: %v ", exp)
 }
 
+// NewErrUnsupportedScope means the builder of the query can not be narrowed by middlewares,
+// such as raw queries
+func NewErrUnsupportedScope(builder any) error {
+	return fmt.Errorf("orm: %T does not support scoping", builder)
+}
+
+// NewErrUnknownFilterField means the filter refers to a field which does not exist or is not allowed.
+// It only contains the key of the input, so it is safe to be returned to the client
+func NewErrUnknownFilterField(key string) error {
+	return fmt.Errorf("orm: unknown filter field %s", key)
+}
+
+// NewErrTooManyFilterValues means the filter has more than max values, such as _in
+func NewErrTooManyFilterValues(key string, max int) error {
+	return fmt.Errorf("orm: too many values of %s, at most %d", key, max)
+}
+
+// NewErrInvalidFilterValue means the value of the filter can not be converted to the type of the field
+func NewErrInvalidFilterValue(key string, val string) error {
+	return fmt.Errorf("orm: invalid filter value %q of %s", val, key)
+}
+
+// NewErrUnsupportedSnapshot means the query does not change rows of a model
+func NewErrUnsupportedSnapshot(builder any) error {
+	return fmt.Errorf("orm: %T does not support snapshot", builder)
+}
+
+// NewErrInvalidFieldValue means val can not be assigned to the field
+func NewErrInvalidFieldValue(fd string, val any) error {
+	return fmt.Errorf("orm: invalid value %v for field %s", val, fd)
+}
+
 func NewErrInvalidTagContent(tag string) error {
 	return fmt.Errorf("orm: invalid tag content %s", tag)
 }
@@ -47,3 +95,14 @@ func NewErrUnsupportedSelectable(s any) error {
 func NewErrUnsupportedAssignableType(exp any) error {
 	return fmt.Errorf("orm: unsupported assignable expression: %v", exp)
 }
+
+// NewErrNoPrimaryKey returns an error representing a model without primary key
+// Tag the key field with orm:"primary_key=true" or name its column "id"
+func NewErrNoPrimaryKey(table string) error {
+	return fmt.Errorf("orm: model %s has no primary key", table)
+}
+
+// NewErrUnsupportedLock returns an error representing the database does not support the row lock
+func NewErrUnsupportedLock(dialect string, lock string) error {
+	return fmt.Errorf("orm: %s does not support row lock %s", dialect, lock)
+}
@@ -1,25 +1,126 @@
 package orm
 
 import (
+	"WebFrame/orm/internal/errs"
 	"WebFrame/orm/model"
 	"context"
+	"regexp"
+	"strings"
 )
 
 type QueryContext struct {
 	Type    string
 	Builder QueryBuilder
 	Model   *model.Model
+
+	q    *Query
+	sess session
+}
+
+// Session is the DB or Tx running a query, see QueryContext.Session.
+// It lets the middlewares, such as the audit sinks, run other queries in the same transaction
+type Session interface {
+	session
+}
+
+// Session returns the session which runs the query,
+// queries run in it share the transaction of the query
+func (qc *QueryContext) Session() Session {
+	return qc.sess
+}
+
+// Query builds the query only once,
+// middlewares should use it instead of calling Builder.Build
+func (qc *QueryContext) Query() (*Query, error) {
+	if qc.q != nil {
+		return qc.q, nil
+	}
+	var err error
+	qc.q, err = qc.Builder.Build()
+	return qc.q, err
+}
+
+// Dialect returns the dialect of the session running the query
+func (qc *QueryContext) Dialect() Dialect {
+	return qc.sess.getCore().dialect
+}
+
+// tableRegexp matches the first table after FROM, INTO, UPDATE or JOIN,
+// the table may be quoted and qualified by the database
+var tableRegexp = regexp.MustCompile("(?i)\\b(?:FROM|INTO|UPDATE|JOIN)\\s+[`\"]?(?:\\w+[`\"]?\\.[`\"]?)?(\\w+)")
+
+// Table returns the table of the model.
+// For raw queries whose T is not a model, the table is extracted from the SQL,
+// and it returns "" if the SQL contains no table
+func (qc *QueryContext) Table() string {
+	if qc.Model != nil {
+		return qc.Model.TableName
+	}
+	q, err := qc.Query()
+	if err != nil {
+		return ""
+	}
+	matches := tableRegexp.FindStringSubmatch(q.SQL)
+	if matches == nil {
+		return ""
+	}
+	return strings.ToLower(matches[1])
 }
 
-//func (qc *QueryContext) Query() (*Query, error) {
-//	if qc.q != nil {
-//		return qc.q, nil
-//	}
-//	var err error
-//	qc.q, err = qc.Builder.Build()
-//	return qc.q, err
-//
-//}
+// AddWhere narrows the query with ps, which are combined with the WHERE clause by AND.
+// It works on Selector, Updater, Deleter and SetQuery, and returns error for the others.
+// Builder is replaced by a narrowed copy, so the builder of the user can be reused,
+// and the query will be rebuilt next time Query is invoked
+func (qc *QueryContext) AddWhere(ps ...Predicate) error {
+	s, ok := qc.Builder.(scopable)
+	if !ok {
+		return errs.NewErrUnsupportedScope(qc.Builder)
+	}
+	qc.Builder = s.scoped(ps...)
+	qc.q = nil
+	return nil
+}
+
+// SetValue sets the field of all the values to be inserted.
+// It works on Inserter only, and returns error for the others.
+// Builder is replaced by a copy carrying the copied values, the values of the user are not modified,
+// and the query will be rebuilt next time Query is invoked
+func (qc *QueryContext) SetValue(field string, val any) error {
+	s, ok := qc.Builder.(valueSetter)
+	if !ok {
+		return errs.NewErrUnsupportedScope(qc.Builder)
+	}
+	b, err := s.withValue(field, val)
+	if err != nil {
+		return err
+	}
+	qc.Builder = b
+	qc.q = nil
+	return nil
+}
+
+// Upsert reports whether the query is an INSERT which updates the existing rows on conflict,
+// such as ON DUPLICATE KEY UPDATE
+func (qc *QueryContext) Upsert() bool {
+	u, ok := qc.Builder.(upserter)
+	return ok && u.isUpsert()
+}
+
+// upserter is implemented by Inserter
+type upserter interface {
+	isUpsert() bool
+}
+
+// scopable is implemented by the builders which have WHERE clause
+type scopable interface {
+	// scoped returns a copy narrowed by ps
+	scoped(ps ...Predicate) QueryBuilder
+}
+
+// valueSetter is implemented by the builders which carry the values of the model
+type valueSetter interface {
+	withValue(field string, val any) (QueryBuilder, error)
+}
 
 type QueryResult struct {
 	// result is different types in different queries
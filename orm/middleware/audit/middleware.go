@@ -0,0 +1,120 @@
+package audit
+
+import (
+	"WebFrame/orm"
+	"context"
+	"time"
+)
+
+// Event records one INSERT, UPDATE or DELETE
+type Event struct {
+	Type  string `json:"type"`
+	Table string `json:"table"`
+	// Keys are the primary keys of the changed rows
+	Keys []any `json:"keys"`
+	// Before is the rows before the change, each row maps the column name to the value.
+	// It is empty for INSERT unless the query is an upsert
+	Before []map[string]any `json:"before"`
+	// After is the rows after the change, it is empty for DELETE
+	After []map[string]any `json:"after"`
+	Actor string           `json:"actor"`
+	Time  time.Time        `json:"time"`
+}
+
+type actorKey struct{}
+
+// WithActor returns a context carrying who is making the changes
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+func actorFromContext(ctx context.Context) string {
+	actor, _ := ctx.Value(actorKey{}).(string)
+	return actor
+}
+
+// MiddlewareBuilder emits an Event to the sink after every successful
+// INSERT, UPDATE and DELETE on a model with primary key, the other models are not audited.
+// The before values are read by a SELECT in the session of the query before it runs,
+// and the after values are read by primary keys after it runs,
+// so the after values of the rows with auto increment keys are not available.
+// UPDATE and DELETE without WHERE are refused, since the whole table would be read.
+// If the sink fails, the error is returned as the error of the query.
+// Put it after the middlewares which modify the query, such as tenant
+type MiddlewareBuilder struct {
+	sink Sink
+	now  func() time.Time
+}
+
+func NewBuilder(sink Sink) *MiddlewareBuilder {
+	return &MiddlewareBuilder{
+		sink: sink,
+		now:  time.Now,
+	}
+}
+
+func (m *MiddlewareBuilder) Build() orm.Middleware {
+	return func(next orm.HandleFunc) orm.HandleFunc {
+		return func(ctx context.Context, qc *orm.QueryContext) *orm.QueryResult {
+			if (qc.Type != "INSERT" && qc.Type != "UPDATE" && qc.Type != "DELETE") ||
+				qc.Model == nil || orm.IsDryRun(ctx) {
+				return next(ctx, qc)
+			}
+			pk, err := qc.Model.PrimaryKey()
+			if err != nil {
+				// the models without primary key, such as join tables, are not audited
+				return next(ctx, qc)
+			}
+			before, err := qc.Snapshot(ctx)
+			if err != nil {
+				return &orm.QueryResult{
+					Err: err,
+				}
+			}
+			res := next(ctx, qc)
+			if res.Err != nil {
+				return res
+			}
+
+			keys := keysOf(before, pk.ColName)
+			var after []map[string]any
+			switch qc.Type {
+			case "INSERT":
+				after, err = qc.Snapshot(ctx)
+				keys = keysOf(after, pk.ColName)
+			case "UPDATE":
+				after, err = qc.SnapshotByKeys(ctx, keys...)
+			}
+			if err != nil {
+				return &orm.QueryResult{
+					Res: res.Res,
+					Err: err,
+				}
+			}
+			err = m.sink.Write(ctx, qc.Session(), Event{
+				Type:   qc.Type,
+				Table:  qc.Model.TableName,
+				Keys:   keys,
+				Before: before,
+				After:  after,
+				Actor:  actorFromContext(ctx),
+				Time:   m.now(),
+			})
+			if err != nil {
+				return &orm.QueryResult{
+					Res: res.Res,
+					Err: err,
+				}
+			}
+			return res
+		}
+	}
+}
+
+func keysOf(rows []map[string]any, col string) []any {
+	keys := make([]any, 0, len(rows))
+	for _, row := range rows {
+		keys = append(keys, row[col])
+	}
+	return keys
+}
@@ -0,0 +1,140 @@
+package audit
+
+import (
+	"WebFrame/orm"
+	"bytes"
+	"context"
+	"encoding/json"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+type User struct {
+	Id   int64
+	Name string
+	Age  int64
+}
+
+func auditDB(t *testing.T, sink Sink) *orm.DB {
+	mb := NewBuilder(sink)
+	mb.now = func() time.Time {
+		return time.UnixMilli(1000)
+	}
+	db, err := orm.Open("sqlite3", "file:"+t.Name()+".db?cache=shared&mode=memory",
+		orm.DBWithDialect(orm.SQLite3), orm.DBWithMiddlewares(mb.Build()))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+	ctx := context.Background()
+	err = orm.RawQuery[any](db, "CREATE TABLE `user`(`id` INTEGER PRIMARY KEY, `name` TEXT, `age` INTEGER)").
+		Exec(ctx).Err()
+	require.NoError(t, err)
+	err = orm.RawQuery[any](db, "CREATE TABLE `audit_log`(`type` TEXT, `table_name` TEXT, `keys` TEXT, "+
+		"`before` TEXT, `after` TEXT, `actor` TEXT, `created_at` INTEGER)").Exec(ctx).Err()
+	require.NoError(t, err)
+	return db
+}
+
+func TestMiddlewareBuilder_LogSink(t *testing.T) {
+	buf := &bytes.Buffer{}
+	db := auditDB(t, NewLogSink(buf))
+	ctx := WithActor(context.Background(), "admin")
+
+	require.NoError(t, orm.NewInserter[User](db).Values(&User{Id: 1, Name: "Tom", Age: 18}).Exec(ctx).Err())
+	require.NoError(t, orm.NewUpdater[User](db).Update(&User{Age: 19}).Set(orm.C("Age")).
+		Where(orm.C("Id").EQ(1)).Exec(ctx).Err())
+	require.NoError(t, orm.NewDeleter[User](db).Where(orm.C("Id").EQ(1)).Exec(ctx).Err())
+	// 查询不会产生审计事件
+	_, err := orm.NewSelector[User](db).GetMulti(ctx)
+	require.NoError(t, err)
+
+	var events []Event
+	dec := json.NewDecoder(buf)
+	for dec.More() {
+		var e Event
+		require.NoError(t, dec.Decode(&e))
+		events = append(events, e)
+	}
+	tom := map[string]any{"id": float64(1), "name": "Tom", "age": float64(18)}
+	updatedTom := map[string]any{"id": float64(1), "name": "Tom", "age": float64(19)}
+	wantTime := time.UnixMilli(1000)
+	assert.Equal(t, 3, len(events))
+	for i := range events {
+		assert.True(t, wantTime.Equal(events[i].Time))
+		events[i].Time = wantTime
+	}
+	assert.Equal(t, []Event{
+		{
+			Type: "INSERT", Table: "user", Keys: []any{float64(1)},
+			Before: []map[string]any{}, After: []map[string]any{tom},
+			Actor: "admin", Time: wantTime,
+		},
+		{
+			Type: "UPDATE", Table: "user", Keys: []any{float64(1)},
+			Before: []map[string]any{tom}, After: []map[string]any{updatedTom},
+			Actor: "admin", Time: wantTime,
+		},
+		{
+			Type: "DELETE", Table: "user", Keys: []any{float64(1)},
+			Before: []map[string]any{updatedTom},
+			Actor:  "admin", Time: wantTime,
+		},
+	}, events)
+}
+
+func TestMiddlewareBuilder_TableSink(t *testing.T) {
+	db := auditDB(t, NewTableSink("audit_log"))
+	ctx := context.Background()
+	countLogs := func() int64 {
+		logs, err := orm.RawQuery[struct{ Cnt int64 }](db, "SELECT COUNT(*) AS `cnt` FROM `audit_log`").Get(ctx)
+		require.NoError(t, err)
+		return logs.Cnt
+	}
+
+	// 审计记录和修改在同一个事务里，一起回滚
+	tx, err := db.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	require.NoError(t, orm.NewInserter[User](tx).Values(&User{Id: 1, Name: "Tom"}).Exec(ctx).Err())
+	require.NoError(t, tx.Rollback())
+	assert.Equal(t, int64(0), countLogs())
+
+	tx, err = db.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	require.NoError(t, orm.NewInserter[User](tx).Values(&User{Id: 1, Name: "Tom"}).Exec(ctx).Err())
+	require.NoError(t, tx.Commit())
+	assert.Equal(t, int64(1), countLogs())
+
+	// dry run 不写审计记录
+	dryCtx, _ := orm.DryRun(ctx)
+	require.NoError(t, orm.NewDeleter[User](db).Exec(dryCtx).Err())
+	assert.Equal(t, int64(1), countLogs())
+}
+
+// UserRole 是没有主键的关联表
+type UserRole struct {
+	UserId int64
+	RoleId int64
+}
+
+func TestMiddlewareBuilder_NoPrimaryKey(t *testing.T) {
+	buf := &bytes.Buffer{}
+	db := auditDB(t, NewLogSink(buf))
+	ctx := context.Background()
+	require.NoError(t, orm.RawQuery[any](db, "CREATE TABLE `user_role`(`user_id` INTEGER, `role_id` INTEGER)").
+		Exec(ctx).Err())
+
+	// 没有主键的模型不审计，但是查询照常执行
+	require.NoError(t, orm.NewInserter[UserRole](db).Values(&UserRole{UserId: 1, RoleId: 2}).Exec(ctx).Err())
+	require.NoError(t, orm.NewUpdater[UserRole](db).Set(orm.Assign("RoleId", 3)).
+		Where(orm.C("UserId").EQ(1)).Exec(ctx).Err())
+	res := orm.NewDeleter[UserRole](db).Where(orm.C("UserId").EQ(1)).Exec(ctx)
+	require.NoError(t, res.Err())
+	affected, err := res.RowsAffected()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), affected)
+	assert.Empty(t, buf.String())
+}
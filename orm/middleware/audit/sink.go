@@ -0,0 +1,78 @@
+package audit
+
+import (
+	"WebFrame/orm"
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+)
+
+// Sink receives the audit events.
+// sess is the session of the audited query,
+// writing in it makes the event a part of the same transaction
+type Sink interface {
+	Write(ctx context.Context, sess orm.Session, e Event) error
+}
+
+type SinkFunc func(ctx context.Context, sess orm.Session, e Event) error
+
+func (f SinkFunc) Write(ctx context.Context, sess orm.Session, e Event) error {
+	return f(ctx, sess, e)
+}
+
+// LogSink writes every event to the writer as a line of JSON
+type LogSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func NewLogSink(w io.Writer) *LogSink {
+	return &LogSink{w: w}
+}
+
+func (l *LogSink) Write(_ context.Context, _ orm.Session, e Event) error {
+	val, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, err = l.w.Write(append(val, '\n'))
+	return err
+}
+
+// TableSink inserts every event into the audit table in the session of the query,
+// so the event is rolled back together with the change.
+// The table is expected to have the columns below,
+// keys, before and after are JSON and created_at is unix milliseconds:
+//
+//	`type`, `table_name`, `keys`, `before`, `after`, `actor`, `created_at`
+type TableSink struct {
+	query string
+}
+
+func NewTableSink(table string) *TableSink {
+	return &TableSink{
+		query: "INSERT INTO `" + table + "`(`type`,`table_name`,`keys`,`before`,`after`,`actor`,`created_at`) " +
+			"VALUES(?,?,?,?,?,?,?);",
+	}
+}
+
+func (t *TableSink) Write(ctx context.Context, sess orm.Session, e Event) error {
+	keys, err := json.Marshal(e.Keys)
+	if err != nil {
+		return err
+	}
+	before, err := json.Marshal(e.Before)
+	if err != nil {
+		return err
+	}
+	after, err := json.Marshal(e.After)
+	if err != nil {
+		return err
+	}
+	// raw queries are not audited, so it will not trigger another event
+	return orm.RawQuery[any](sess, t.query, e.Type, e.Table, string(keys), string(before), string(after),
+		e.Actor, e.Time.UnixMilli()).Exec(ctx).Err()
+}
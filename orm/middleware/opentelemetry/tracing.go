@@ -2,16 +2,29 @@ package opentelemetry
 
 import (
 	"WebFrame/orm"
+	"WebFrame/orm/internal/errs"
 	"context"
+	"database/sql"
+	"errors"
+	"fmt"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
+	"strings"
 )
 
 const defaultInstrumentationName = "./middleware/opentelemetry"
 
+// MiddlewareBuilder creates a client span for every query,
+// following the OpenTelemetry semantic conventions of database.
+// The span is the child of the span in ctx,
+// so pass web.Context.Req.Context() to link it with the web opentelemetry middleware
 type MiddlewareBuilder struct {
 	Tracer trace.Tracer
+	// RecordArgs records the args of the statement as db.statement.args.
+	// The args may contain personal data, so they are not recorded by default
+	RecordArgs bool
 }
 
 func (b MiddlewareBuilder) Build() orm.Middleware {
@@ -20,19 +33,78 @@ func (b MiddlewareBuilder) Build() orm.Middleware {
 	}
 	return func(next orm.HandleFunc) orm.HandleFunc {
 		return func(ctx context.Context, qc *orm.QueryContext) *orm.QueryResult {
-			tbl := qc.Model.TableName
-			reqCtx, span := b.Tracer.Start(ctx, qc.Type+"-"+tbl, trace.WithAttributes())
+			reqCtx, span := b.Tracer.Start(ctx, qc.Type,
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(
+					attribute.String("component", "orm"),
+					attribute.String("db.system", qc.Dialect().Name()),
+				))
 			defer span.End()
-			span.SetAttributes(attribute.String("component", "orm"))
-			q, err := qc.Builder.Build()
+
+			res := next(reqCtx, qc)
+			// the statement is read after next returns,
+			// so that it contains the changes of the later middlewares, such as tenant
+			q, err := qc.Query()
+			op := qc.Type
+			if q != nil && (op == "RAW" || op == "") {
+				op = operationOf(q.SQL)
+			}
+			tbl := qc.Table()
+			spanName := op
+			if tbl != "" {
+				spanName = op + " " + tbl
+			}
+			span.SetName(spanName)
+			attrs := []attribute.KeyValue{
+				attribute.String("db.operation", op),
+			}
+			if tbl != "" {
+				attrs = append(attrs, attribute.String("db.sql.table", tbl))
+			}
+			if q != nil {
+				attrs = append(attrs, attribute.String("db.statement", q.SQL))
+				if b.RecordArgs && len(q.Args) > 0 {
+					attrs = append(attrs, attribute.StringSlice("db.statement.args", argsOf(q.Args)))
+				}
+			}
+			span.SetAttributes(attrs...)
 			if err != nil {
 				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
 			}
-			span.SetAttributes(attribute.String("table", tbl))
-			if q != nil {
-				span.SetAttributes(attribute.String("sql", q.SQL))
+			if isFailure(res.Err) {
+				span.RecordError(res.Err)
+				span.SetStatus(codes.Error, res.Err.Error())
 			}
-			return next(reqCtx, qc)
+			if r, ok := res.Res.(sql.Result); ok {
+				if affected, er := r.RowsAffected(); er == nil {
+					span.SetAttributes(attribute.Int64("db.rows_affected", affected))
+				}
+			}
+			return res
 		}
 	}
 }
+
+// isFailure reports whether err means the query failed,
+// finding no rows is a normal result of a query
+func isFailure(err error) bool {
+	return err != nil &&
+		!errors.Is(err, errs.ErrNoRows) &&
+		!errors.Is(err, sql.ErrNoRows) &&
+		!errors.Is(err, orm.ErrDryRun)
+}
+
+// operationOf returns the first keyword of the statement, such as SELECT
+func operationOf(query string) string {
+	op, _, _ := strings.Cut(strings.TrimSpace(query), " ")
+	return strings.ToUpper(op)
+}
+
+func argsOf(args []any) []string {
+	res := make([]string, 0, len(args))
+	for _, arg := range args {
+		res = append(res, fmt.Sprintf("%v", arg))
+	}
+	return res
+}
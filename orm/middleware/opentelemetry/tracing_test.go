@@ -0,0 +1,194 @@
+package opentelemetry
+
+import (
+	"WebFrame/orm"
+	"context"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"testing"
+)
+
+type User struct {
+	Id   int64
+	Name string
+}
+
+func TestMiddlewareBuilder_Build(t *testing.T) {
+	testCases := []struct {
+		name       string
+		recordArgs bool
+		// ms run after the tracing middleware
+		ms         []orm.Middleware
+		mock       func(mock sqlmock.Sqlmock)
+		run        func(ctx context.Context, db *orm.DB) error
+		wantName   string
+		wantAttrs  []attribute.KeyValue
+		wantStatus codes.Code
+	}{
+		{
+			// 原生查询没有 Model，以前会 panic
+			name:       "raw query",
+			recordArgs: true,
+			mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec("(?i)UPDATE .*").WillReturnResult(sqlmock.NewResult(0, 2))
+			},
+			run: func(ctx context.Context, db *orm.DB) error {
+				return orm.RawQuery[any](db, "update `user` set `name` = ?", "Tom").Exec(ctx).Err()
+			},
+			wantName: "UPDATE user",
+			wantAttrs: []attribute.KeyValue{
+				attribute.String("component", "orm"),
+				attribute.String("db.system", "mysql"),
+				attribute.String("db.operation", "UPDATE"),
+				attribute.String("db.sql.table", "user"),
+				attribute.String("db.statement", "update `user` set `name` = ?"),
+				attribute.StringSlice("db.statement.args", []string{"Tom"}),
+				attribute.Int64("db.rows_affected", 2),
+			},
+		},
+		{
+			name: "error",
+			mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT .*").WillReturnError(errors.New("mock error"))
+			},
+			run: func(ctx context.Context, db *orm.DB) error {
+				_, err := orm.NewSelector[User](db).Where(orm.C("Id").EQ(1)).Get(ctx)
+				return err
+			},
+			wantName: "SELECT user",
+			wantAttrs: []attribute.KeyValue{
+				attribute.String("component", "orm"),
+				attribute.String("db.system", "mysql"),
+				attribute.String("db.operation", "SELECT"),
+				attribute.String("db.sql.table", "user"),
+				attribute.String("db.statement", "SELECT * FROM `user` WHERE `id` = ?;"),
+			},
+			wantStatus: codes.Error,
+		},
+		{
+			// 默认不记录参数
+			name: "args not recorded",
+			mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec("DELETE .*").WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			run: func(ctx context.Context, db *orm.DB) error {
+				return orm.NewDeleter[User](db).Where(orm.C("Name").EQ("Tom")).Exec(ctx).Err()
+			},
+			wantName: "DELETE user",
+			wantAttrs: []attribute.KeyValue{
+				attribute.String("component", "orm"),
+				attribute.String("db.system", "mysql"),
+				attribute.String("db.operation", "DELETE"),
+				attribute.String("db.sql.table", "user"),
+				attribute.String("db.statement", "DELETE FROM `user` WHERE `name` = ?;"),
+				attribute.Int64("db.rows_affected", 1),
+			},
+		},
+		{
+			// 没有数据不是错误
+			name: "no rows",
+			mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id", "name"}))
+			},
+			run: func(ctx context.Context, db *orm.DB) error {
+				_, err := orm.NewSelector[User](db).Where(orm.C("Id").EQ(1)).Get(ctx)
+				return err
+			},
+			wantName: "SELECT user",
+			wantAttrs: []attribute.KeyValue{
+				attribute.String("component", "orm"),
+				attribute.String("db.system", "mysql"),
+				attribute.String("db.operation", "SELECT"),
+				attribute.String("db.sql.table", "user"),
+				attribute.String("db.statement", "SELECT * FROM `user` WHERE `id` = ?;"),
+			},
+			wantStatus: codes.Unset,
+		},
+		{
+			name: "dry run",
+			mock: func(mock sqlmock.Sqlmock) {},
+			run: func(ctx context.Context, db *orm.DB) error {
+				ctx, _ = orm.DryRun(ctx)
+				_, err := orm.NewSelector[User](db).Where(orm.C("Id").EQ(1)).Get(ctx)
+				return err
+			},
+			wantName: "SELECT user",
+			wantAttrs: []attribute.KeyValue{
+				attribute.String("component", "orm"),
+				attribute.String("db.system", "mysql"),
+				attribute.String("db.operation", "SELECT"),
+				attribute.String("db.sql.table", "user"),
+				attribute.String("db.statement", "SELECT * FROM `user` WHERE `id` = ?;"),
+			},
+			wantStatus: codes.Unset,
+		},
+		{
+			// 后面的 middleware 修改了查询，span 里面是最终执行的语句
+			name:       "modified by later middleware",
+			recordArgs: true,
+			ms: []orm.Middleware{func(next orm.HandleFunc) orm.HandleFunc {
+				return func(ctx context.Context, qc *orm.QueryContext) *orm.QueryResult {
+					if err := qc.AddWhere(orm.C("Name").EQ("Tom")); err != nil {
+						return &orm.QueryResult{Err: err}
+					}
+					return next(ctx, qc)
+				}
+			}},
+			mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec("DELETE .*").WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			run: func(ctx context.Context, db *orm.DB) error {
+				return orm.NewDeleter[User](db).Where(orm.C("Id").EQ(1)).Exec(ctx).Err()
+			},
+			wantName: "DELETE user",
+			wantAttrs: []attribute.KeyValue{
+				attribute.String("component", "orm"),
+				attribute.String("db.system", "mysql"),
+				attribute.String("db.operation", "DELETE"),
+				attribute.String("db.sql.table", "user"),
+				attribute.String("db.statement", "DELETE FROM `user` WHERE (`id` = ?) AND (`name` = ?);"),
+				attribute.StringSlice("db.statement.args", []string{"1", "Tom"}),
+				attribute.Int64("db.rows_affected", 1),
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sr := tracetest.NewSpanRecorder()
+			tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+			tracer := tp.Tracer("test")
+			mockDB, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			db, err := orm.OpenDB(mockDB, orm.DBWithMiddlewares(MiddlewareBuilder{
+				Tracer:     tracer,
+				RecordArgs: tc.recordArgs,
+			}.Build()), orm.DBWithMiddlewares(tc.ms...))
+			require.NoError(t, err)
+			defer func() {
+				_ = db.Close()
+			}()
+			tc.mock(mock)
+
+			// 模拟 web 的 opentelemetry middleware 放进请求 context 的 span
+			ctx, parent := tracer.Start(context.Background(), "/users")
+			_ = tc.run(ctx, db)
+			parent.End()
+
+			spans := sr.Ended()
+			require.Equal(t, 2, len(spans))
+			span := spans[0]
+			assert.Equal(t, tc.wantName, span.Name())
+			assert.Equal(t, trace.SpanKindClient, span.SpanKind())
+			assert.Equal(t, tc.wantAttrs, span.Attributes())
+			assert.Equal(t, tc.wantStatus, span.Status().Code)
+			assert.Equal(t, parent.SpanContext().SpanID(), span.Parent().SpanID())
+		})
+	}
+}
@@ -2,42 +2,182 @@ package prometheus
 
 import (
 	"WebFrame/orm"
+	"WebFrame/orm/internal/errs"
 	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
 	"github.com/prometheus/client_golang/prometheus"
+	"reflect"
 	"time"
 )
 
+// MiddlewareBuilder reports the RED metrics of the queries, all labeled with type and table:
+//
+//	<Name>_duration_seconds   histogram of the durations
+//	<Name>_total              counter of the queries
+//	<Name>_errors_total       counter of the failed queries, labeled with error class,
+//	                          no rows found and dry-run are not failures
+//	<Name>_rows_affected      gauge of the rows affected by the last INSERT, UPDATE, DELETE or raw exec
+//	<Name>_rows_returned      gauge of the rows returned by the last query
+//
+// The table of a raw query is extracted from its SQL if T of RawQuery is not a model
 type MiddlewareBuilder struct {
 	Name        string
 	Subsystem   string
 	ConstLabels map[string]string
 	Help        string
+	// Buckets of the duration histogram in seconds, prometheus.DefBuckets by default
+	Buckets []float64
+	// ErrorClass maps an error to the value of the error label, ErrorClass by default
+	ErrorClass func(err error) string
+	// Registerer registers the metrics, prometheus.DefaultRegisterer by default.
+	// If the metrics are already registered, for example Build is called for another DB,
+	// the registered ones are shared
+	Registerer prometheus.Registerer
 }
 
 func (m MiddlewareBuilder) Build() orm.Middleware {
-	summaryVec := prometheus.NewSummaryVec(prometheus.SummaryOpts{
-		Name:        m.Name,
+	labels := []string{"type", "table"}
+	histogramVec := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:        m.Name + "_duration_seconds",
 		Subsystem:   m.Subsystem,
 		ConstLabels: m.ConstLabels,
 		Help:        m.Help,
-	}, []string{"type", "table"})
+		Buckets:     m.Buckets,
+	}, labels)
+	totalVec := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name:        m.Name + "_total",
+		Subsystem:   m.Subsystem,
+		ConstLabels: m.ConstLabels,
+		Help:        "The total number of queries.",
+	}, labels)
+	errVec := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name:        m.Name + "_errors_total",
+		Subsystem:   m.Subsystem,
+		ConstLabels: m.ConstLabels,
+		Help:        "The total number of failed queries.",
+	}, append(labels, "error"))
+	affectedVec := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name:        m.Name + "_rows_affected",
+		Subsystem:   m.Subsystem,
+		ConstLabels: m.ConstLabels,
+		Help:        "The number of rows affected by the last query.",
+	}, labels)
+	returnedVec := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name:        m.Name + "_rows_returned",
+		Subsystem:   m.Subsystem,
+		ConstLabels: m.ConstLabels,
+		Help:        "The number of rows returned by the last query.",
+	}, labels)
+	reg := m.Registerer
+	if reg == nil {
+		reg = prometheus.DefaultRegisterer
+	}
+	histogramVec = register(reg, histogramVec)
+	totalVec = register(reg, totalVec)
+	errVec = register(reg, errVec)
+	affectedVec = register(reg, affectedVec)
+	returnedVec = register(reg, returnedVec)
+	errClass := m.ErrorClass
+	if errClass == nil {
+		errClass = ErrorClass
+	}
 
 	return func(next orm.HandleFunc) orm.HandleFunc {
 		return func(ctx context.Context, qc *orm.QueryContext) *orm.QueryResult {
 			startTime := time.Now()
-			defer func() {
-				endTime := time.Now()
-				typ := "unknown"
-				// 原生查询才会走到这里
-				tblName := "unknown"
-				if qc.Model != nil {
-					typ = qc.Model.TableName
-					tblName = qc.Model.TableName
-				}
-				summaryVec.WithLabelValues(typ, tblName).
-					Observe(float64(endTime.Sub(startTime).Milliseconds()))
-			}()
-			return next(ctx, qc)
+			res := next(ctx, qc)
+			typ, tbl := qc.Type, tableOf(qc)
+			histogramVec.WithLabelValues(typ, tbl).Observe(time.Since(startTime).Seconds())
+			totalVec.WithLabelValues(typ, tbl).Inc()
+			if isFailure(res.Err) {
+				errVec.WithLabelValues(typ, tbl, errClass(res.Err)).Inc()
+			}
+			if affected, ok := rowsAffected(res); ok {
+				affectedVec.WithLabelValues(typ, tbl).Set(float64(affected))
+			} else if returned, ok := rowsReturned(res); ok && !isWrite(typ) {
+				returnedVec.WithLabelValues(typ, tbl).Set(float64(returned))
+			}
+			return res
 		}
 	}
 }
+
+// ErrorClass classifies the common errors, the others are "other"
+func ErrorClass(err error) string {
+	switch {
+	case errors.Is(err, errs.ErrNoRows):
+		return "no_rows"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case errors.Is(err, driver.ErrBadConn):
+		return "bad_conn"
+	case errors.Is(err, sql.ErrTxDone):
+		return "tx_done"
+	default:
+		return "other"
+	}
+}
+
+// register returns the registered collector if c is already registered,
+// and panics for the other errors like prometheus.MustRegister
+func register[C prometheus.Collector](reg prometheus.Registerer, c C) C {
+	err := reg.Register(c)
+	if err == nil {
+		return c
+	}
+	var are prometheus.AlreadyRegisteredError
+	if errors.As(err, &are) {
+		if existing, ok := are.ExistingCollector.(C); ok {
+			return existing
+		}
+	}
+	panic(err)
+}
+
+// isFailure reports whether err means the query failed,
+// finding no rows is a normal result of a query
+func isFailure(err error) bool {
+	return err != nil &&
+		!errors.Is(err, errs.ErrNoRows) &&
+		!errors.Is(err, sql.ErrNoRows) &&
+		!errors.Is(err, orm.ErrDryRun)
+}
+
+func tableOf(qc *orm.QueryContext) string {
+	if tbl := qc.Table(); tbl != "" {
+		return tbl
+	}
+	return "unknown"
+}
+
+func isWrite(typ string) bool {
+	return typ == "INSERT" || typ == "UPDATE" || typ == "DELETE"
+}
+
+func rowsAffected(res *orm.QueryResult) (int64, bool) {
+	r, ok := res.Res.(sql.Result)
+	if !ok {
+		return 0, false
+	}
+	affected, err := r.RowsAffected()
+	return affected, err == nil
+}
+
+// rowsReturned counts the slice returned by GetMulti, or a single row returned by Get
+func rowsReturned(res *orm.QueryResult) (int, bool) {
+	if res.Err != nil && !errors.Is(res.Err, errs.ErrNoRows) {
+		return 0, false
+	}
+	if res.Res == nil {
+		return 0, true
+	}
+	val := reflect.ValueOf(res.Res)
+	if val.Kind() == reflect.Slice {
+		return val.Len(), true
+	}
+	return 1, true
+}
@@ -0,0 +1,114 @@
+package prometheus
+
+import (
+	"WebFrame/orm"
+	"context"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+)
+
+type User struct {
+	Id   int64
+	Name string
+}
+
+func TestMiddlewareBuilder_Build(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	db, err := orm.OpenDB(mockDB, orm.DBWithMiddlewares(MiddlewareBuilder{
+		Name:       "orm",
+		Help:       "The durations of the queries.",
+		Registerer: reg,
+	}.Build()))
+	require.NoError(t, err)
+	defer func() {
+		_ = db.Close()
+	}()
+	ctx := context.Background()
+
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).
+		AddRow(1, "Tom").AddRow(2, "Jerry"))
+	_, err = orm.NewSelector[User](db).GetMulti(ctx)
+	require.NoError(t, err)
+
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id", "name"}))
+	_, err = orm.NewSelector[User](db).Get(ctx)
+	// 没有数据不算失败
+	require.Error(t, err)
+
+	mock.ExpectQuery("SELECT .*").WillReturnError(errors.New("mock error"))
+	_, err = orm.NewSelector[User](db).GetMulti(ctx)
+	require.Error(t, err)
+
+	mock.ExpectExec("DELETE .*").WillReturnResult(sqlmock.NewResult(0, 3))
+	require.NoError(t, orm.NewDeleter[User](db).Exec(ctx).Err())
+
+	// 原生查询从 SQL 里面解析表名
+	mock.ExpectExec("UPDATE .*").WillReturnResult(sqlmock.NewResult(0, 1))
+	require.NoError(t, orm.RawQuery[any](db, "UPDATE `shop`.`order` SET `paid` = 1").Exec(ctx).Err())
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	err = testutil.GatherAndCompare(reg, strings.NewReader(`
+# HELP orm_errors_total The total number of failed queries.
+# TYPE orm_errors_total counter
+orm_errors_total{error="other",table="user",type="SELECT"} 1
+# HELP orm_rows_affected The number of rows affected by the last query.
+# TYPE orm_rows_affected gauge
+orm_rows_affected{table="order",type="RAW"} 1
+orm_rows_affected{table="user",type="DELETE"} 3
+# HELP orm_rows_returned The number of rows returned by the last query.
+# TYPE orm_rows_returned gauge
+orm_rows_returned{table="user",type="SELECT"} 0
+# HELP orm_total The total number of queries.
+# TYPE orm_total counter
+orm_total{table="order",type="RAW"} 1
+orm_total{table="user",type="DELETE"} 1
+orm_total{table="user",type="SELECT"} 3
+`), "orm_errors_total", "orm_rows_affected", "orm_rows_returned", "orm_total")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, testutil.CollectAndCount(reg, "orm_duration_seconds"))
+}
+
+func TestMiddlewareBuilder_BuildTwice(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	builder := MiddlewareBuilder{
+		Name:       "orm",
+		Help:       "The durations of the queries.",
+		Registerer: reg,
+	}
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	// 两个 DB 共享同一组指标
+	db1, err := orm.OpenDB(mockDB, orm.DBWithMiddlewares(builder.Build()))
+	require.NoError(t, err)
+	var db2 *orm.DB
+	require.NotPanics(t, func() {
+		db2, err = orm.OpenDB(mockDB, orm.DBWithMiddlewares(builder.Build()))
+	})
+	require.NoError(t, err)
+	ctx := context.Background()
+	mock.ExpectExec("DELETE .*").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE .*").WillReturnResult(sqlmock.NewResult(0, 1))
+	require.NoError(t, orm.NewDeleter[User](db1).Exec(ctx).Err())
+	require.NoError(t, orm.NewDeleter[User](db2).Exec(ctx).Err())
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	err = testutil.GatherAndCompare(reg, strings.NewReader(`
+# HELP orm_total The total number of queries.
+# TYPE orm_total counter
+orm_total{table="user",type="DELETE"} 2
+`), "orm_total")
+	assert.NoError(t, err)
+
+	// 同名但是 help 不同的指标依旧 panic
+	assert.Panics(t, func() {
+		MiddlewareBuilder{Name: "orm", Help: "another", Registerer: reg}.Build()
+	})
+}
@@ -0,0 +1,37 @@
+package prometheus
+
+import (
+	"WebFrame/orm"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+)
+
+func TestStatsCollector(t *testing.T) {
+	mockDB, _, err := sqlmock.New()
+	require.NoError(t, err)
+	db, err := orm.OpenDB(mockDB, orm.DBWithMaxOpenConns(10))
+	require.NoError(t, err)
+	defer func() {
+		_ = db.Close()
+	}()
+	c := NewStatsCollector(db, "webframe", "db", map[string]string{"db": "test"})
+	err = testutil.CollectAndCompare(c, strings.NewReader(`
+# HELP webframe_db_max_open_connections Maximum number of open connections to the database.
+# TYPE webframe_db_max_open_connections gauge
+webframe_db_max_open_connections{db="test"} 10
+# HELP webframe_db_in_use_connections The number of connections currently in use.
+# TYPE webframe_db_in_use_connections gauge
+webframe_db_in_use_connections{db="test"} 0
+# HELP webframe_db_wait_count_total The total number of connections waited for.
+# TYPE webframe_db_wait_count_total counter
+webframe_db_wait_count_total{db="test"} 0
+`), "webframe_db_max_open_connections", "webframe_db_in_use_connections", "webframe_db_wait_count_total")
+	assert.NoError(t, err)
+	problems, err := testutil.CollectAndLint(c)
+	require.NoError(t, err)
+	assert.Empty(t, problems)
+}
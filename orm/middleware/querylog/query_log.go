@@ -26,7 +26,7 @@ func NewBuilder() *MiddlewareBuilder {
 func (m *MiddlewareBuilder) Build() orm.Middleware {
 	return func(next orm.HandleFunc) orm.HandleFunc {
 		return func(ctx context.Context, qc *orm.QueryContext) *orm.QueryResult {
-			q, err := qc.Builder.Build()
+			q, err := qc.Query()
 			if err != nil {
 				return &orm.QueryResult{
 					Err: err,
@@ -0,0 +1,93 @@
+package tenant
+
+import (
+	"WebFrame/orm"
+	"context"
+	"errors"
+)
+
+// ErrNoTenant means the query touches a tenant model,
+// but there is no tenant in the context and it is not bypassed
+var ErrNoTenant = errors.New("orm: no tenant in context")
+
+// ErrUpsert means the query is an upsert of a tenant model, which is refused,
+// since the existing row having the same key may belong to another tenant and would be taken over
+var ErrUpsert = errors.New("orm: upsert of tenant model is not supported")
+
+type tenantKey struct{}
+
+type bypassKey struct{}
+
+// WithTenant returns a context carrying the tenant id
+func WithTenant(ctx context.Context, id any) context.Context {
+	return context.WithValue(ctx, tenantKey{}, id)
+}
+
+// FromContext returns the tenant id set by WithTenant
+func FromContext(ctx context.Context) (any, bool) {
+	id := ctx.Value(tenantKey{})
+	return id, id != nil
+}
+
+// Bypass returns a context under which the queries are not scoped,
+// use it for the cross-tenant jobs only
+func Bypass(ctx context.Context) context.Context {
+	return context.WithValue(ctx, bypassKey{}, true)
+}
+
+func bypassed(ctx context.Context) bool {
+	b, _ := ctx.Value(bypassKey{}).(bool)
+	return b
+}
+
+// MiddlewareBuilder scopes the queries of the models which have a field
+// tagged with orm:"tenant=true" to the tenant in the context.
+// SELECT, DELETE and EXPLAIN get an extra predicate tenant = ?,
+// and INSERT sets the tenant on the copies of the values.
+// Upserts, including Repository.Save, are refused with ErrUpsert.
+// The builders and the values of the user are never modified, so they can be reused across tenants.
+// The other queries of those models, such as raw queries, are refused.
+// It modifies the query, so put it before the middlewares reading the query
+type MiddlewareBuilder struct {
+}
+
+func NewMiddlewareBuilder() *MiddlewareBuilder {
+	return &MiddlewareBuilder{}
+}
+
+func (m *MiddlewareBuilder) Build() orm.Middleware {
+	return func(next orm.HandleFunc) orm.HandleFunc {
+		return func(ctx context.Context, qc *orm.QueryContext) *orm.QueryResult {
+			if qc.Model == nil {
+				return next(ctx, qc)
+			}
+			fd, ok := qc.Model.TenantField()
+			if !ok || bypassed(ctx) {
+				return next(ctx, qc)
+			}
+			id, ok := FromContext(ctx)
+			if !ok {
+				return &orm.QueryResult{
+					Err: ErrNoTenant,
+				}
+			}
+			var err error
+			if qc.Type == "INSERT" {
+				if qc.Upsert() {
+					return &orm.QueryResult{
+						Err: ErrUpsert,
+					}
+				}
+				err = qc.SetValue(fd.GoName, id)
+			} else {
+				err = qc.AddWhere(orm.C(fd.GoName).EQ(id))
+			}
+			if err != nil {
+				return &orm.QueryResult{
+					Err: err,
+				}
+			}
+			return next(ctx, qc)
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package tenant
+
+import (
+	"WebFrame/orm"
+	"WebFrame/orm/internal/errs"
+	"context"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+type Order struct {
+	Id       int64
+	TenantId int64 `orm:"tenant=true"`
+	Amount   int64
+}
+
+type Config struct {
+	Id  int64
+	Key string
+}
+
+func TestMiddlewareBuilder_Build(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = mockDB.Close() }()
+	db, err := orm.OpenDB(mockDB, orm.DBWithMiddlewares(NewMiddlewareBuilder().Build()))
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name      string
+		ctx       context.Context
+		run       func(ctx context.Context) error
+		wantQuery *orm.Query
+		wantErr   error
+	}{
+		{
+			name: "select",
+			ctx:  WithTenant(context.Background(), 12),
+			run: func(ctx context.Context) error {
+				_, err := orm.NewSelector[Order](db).Where(orm.C("Amount").GT(10)).GetMulti(ctx)
+				return err
+			},
+			wantQuery: &orm.Query{
+				SQL:  "SELECT * FROM `order` WHERE (`amount` > ?) AND (`tenant_id` = ?);",
+				Args: []any{10, 12},
+			},
+		},
+		{
+			name: "delete",
+			ctx:  WithTenant(context.Background(), 12),
+			run: func(ctx context.Context) error {
+				return orm.NewDeleter[Order](db).Exec(ctx).Err()
+			},
+			wantQuery: &orm.Query{
+				SQL:  "DELETE FROM `order` WHERE `tenant_id` = ?;",
+				Args: []any{12},
+			},
+		},
+		{
+			name: "insert",
+			ctx:  WithTenant(context.Background(), 12),
+			run: func(ctx context.Context) error {
+				return orm.NewInserter[Order](db).Columns("Id", "Amount").
+					Values(&Order{Id: 1, Amount: 100}).Exec(ctx).Err()
+			},
+			wantQuery: &orm.Query{
+				SQL:  "INSERT INTO `order`(`id`, `amount`, `tenant_id`) VALUES(?,?,?);",
+				Args: []any{int64(1), int64(100), int64(12)},
+			},
+		},
+		{
+			name: "no tenant",
+			ctx:  context.Background(),
+			run: func(ctx context.Context) error {
+				_, err := orm.NewSelector[Order](db).Get(ctx)
+				return err
+			},
+			wantErr: ErrNoTenant,
+		},
+		{
+			name: "bypass",
+			ctx:  Bypass(context.Background()),
+			run: func(ctx context.Context) error {
+				_, err := orm.NewSelector[Order](db).Get(ctx)
+				return err
+			},
+			wantQuery: &orm.Query{
+				SQL: "SELECT * FROM `order`;",
+			},
+		},
+		{
+			name: "raw query",
+			ctx:  WithTenant(context.Background(), 12),
+			run: func(ctx context.Context) error {
+				_, err := orm.RawQuery[Order](db, "SELECT * FROM `order`").Get(ctx)
+				return err
+			},
+			wantErr: errs.NewErrUnsupportedScope(&orm.RawQuerier[Order]{}),
+		},
+		{
+			// 没有 tenant 字段的模型不受影响
+			name: "not tenant model",
+			ctx:  context.Background(),
+			run: func(ctx context.Context) error {
+				_, err := orm.NewSelector[Config](db).Get(ctx)
+				return err
+			},
+			wantQuery: &orm.Query{
+				SQL: "SELECT * FROM `config`;",
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, rec := orm.DryRun(tc.ctx)
+			err := tc.run(ctx)
+			// the queries reading data return ErrDryRun once they reach the database
+			if errors.Is(err, orm.ErrDryRun) {
+				err = nil
+			}
+			assert.Equal(t, tc.wantErr, err)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, []*orm.Query{tc.wantQuery}, rec.Queries())
+		})
+	}
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMiddlewareBuilder_Reuse(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = mockDB.Close() }()
+	db, err := orm.OpenDB(mockDB, orm.DBWithMiddlewares(NewMiddlewareBuilder().Build()))
+	require.NoError(t, err)
+
+	// 同一个 builder 在两个租户下执行，条件不会累积
+	s := orm.NewSelector[Order](db).Where(orm.C("Amount").GT(10))
+	d := orm.NewDeleter[Order](db).Where(orm.C("Id").EQ(1))
+	val := &Order{Id: 1, Amount: 100}
+	i := orm.NewInserter[Order](db).Columns("Id", "Amount").Values(val)
+	for _, tenant := range []int64{1, 2} {
+		ctx, rec := orm.DryRun(WithTenant(context.Background(), tenant))
+		_, err = s.GetMulti(ctx)
+		assert.Equal(t, orm.ErrDryRun, err)
+		require.NoError(t, d.Exec(ctx).Err())
+		require.NoError(t, i.Exec(ctx).Err())
+		assert.Equal(t, []*orm.Query{
+			{
+				SQL:  "SELECT * FROM `order` WHERE (`amount` > ?) AND (`tenant_id` = ?);",
+				Args: []any{10, tenant},
+			},
+			{
+				SQL:  "DELETE FROM `order` WHERE (`id` = ?) AND (`tenant_id` = ?);",
+				Args: []any{1, tenant},
+			},
+			{
+				SQL:  "INSERT INTO `order`(`id`, `amount`, `tenant_id`) VALUES(?,?,?);",
+				Args: []any{int64(1), int64(100), tenant},
+			},
+		}, rec.Queries())
+	}
+	// the values of the user are not modified
+	assert.Equal(t, &Order{Id: 1, Amount: 100}, val)
+
+	// the builder of the user is not narrowed
+	q, err := s.Build()
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM `order` WHERE `amount` > ?;", q.SQL)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMiddlewareBuilder_Upsert(t *testing.T) {
+	db, err := orm.Open("sqlite3", "file:tenant_upsert.db?cache=shared&mode=memory",
+		orm.DBWithDialect(orm.SQLite3), orm.DBWithMiddlewares(NewMiddlewareBuilder().Build()))
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+	err = orm.RawQuery[any](db, "CREATE TABLE `order`(`id` INTEGER PRIMARY KEY, `tenant_id` INTEGER, `amount` INTEGER)").
+		Exec(context.Background()).Err()
+	require.NoError(t, err)
+
+	// 两个租户使用同一个主键，租户 1 不能通过 upsert 抢走租户 2 的数据
+	ctx1 := WithTenant(context.Background(), int64(1))
+	ctx2 := WithTenant(context.Background(), int64(2))
+	repo := orm.NewRepository[Order](db)
+	require.NoError(t, repo.Insert(ctx2, &Order{Id: 5, Amount: 100}).Err())
+	assert.Equal(t, ErrUpsert, repo.Save(ctx1, &Order{Id: 5, Amount: 1}).Err())
+	assert.Equal(t, ErrUpsert, orm.NewInserter[Order](db).Values(&Order{Id: 5, Amount: 1}).
+		OnDuplicateKey().ConflictColumns("Id").Update(orm.C("Amount")).Exec(ctx1).Err())
+	// 普通插入只会主键冲突
+	assert.Error(t, repo.Insert(ctx1, &Order{Id: 5, Amount: 1}).Err())
+
+	order, err := repo.FindByID(ctx2, 5)
+	require.NoError(t, err)
+	assert.Equal(t, &Order{Id: 5, TenantId: 2, Amount: 100}, order)
+	_, err = repo.FindByID(ctx1, 5)
+	assert.Equal(t, errs.ErrNoRows, err)
+}
@@ -0,0 +1,45 @@
+package orm
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestQueryContext_Table(t *testing.T) {
+	db := memoryDB(t)
+	testCases := []struct {
+		name string
+		qc   *QueryContext
+		want string
+	}{
+		{
+			name: "model",
+			qc:   &QueryContext{Builder: RawQuery[any](db, "SELECT 1"), Model: modelOf[TestModel](db.core)},
+			want: "test_model",
+		},
+		{
+			name: "select",
+			qc:   &QueryContext{Builder: RawQuery[any](db, "SELECT * FROM `user` WHERE id = ?")},
+			want: "user",
+		},
+		{
+			name: "insert",
+			qc:   &QueryContext{Builder: RawQuery[any](db, "insert into order_item(id) values(?)")},
+			want: "order_item",
+		},
+		{
+			name: "qualified",
+			qc:   &QueryContext{Builder: RawQuery[any](db, `DELETE FROM "shop"."Order"`)},
+			want: "order",
+		},
+		{
+			name: "no table",
+			qc:   &QueryContext{Builder: RawQuery[any](db, "SELECT 1")},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.qc.Table())
+		})
+	}
+}
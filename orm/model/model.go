@@ -1,6 +1,7 @@
 package model
 
 import (
+	"WebFrame/orm/internal/errs"
 	"reflect"
 )
 
@@ -19,15 +20,46 @@ type Field struct {
 	Type    reflect.Type
 	Index   int
 	Offset  uintptr
+	// PrimaryKey is set by the tag orm:"primary_key=true"
+	PrimaryKey bool
+	// Tenant is set by the tag orm:"tenant=true",
+	// it marks the column which isolates the rows of different tenants
+	Tenant bool
 }
 
 // We put all the keys of the tags we support here
 // to make it easier for users to find and for us to maintain
 const (
-	tagKeyColumn = "column"
+	tagKeyColumn     = "column"
+	tagKeyPrimaryKey = "primary_key"
+	tagKeyTenant     = "tenant"
 )
 
 // TableName is an interface that users can implement to return a custom table name
 type TableName interface {
 	TableName() string
 }
+
+// PrimaryKey returns the field tagged with primary_key=true.
+// If no field is tagged, the field mapped to column "id" is used by convention
+func (m *Model) PrimaryKey() (*Field, error) {
+	for _, fd := range m.Fields {
+		if fd.PrimaryKey {
+			return fd, nil
+		}
+	}
+	if fd, ok := m.ColumnMap["id"]; ok {
+		return fd, nil
+	}
+	return nil, errs.NewErrNoPrimaryKey(m.TableName)
+}
+
+// TenantField returns the field tagged with tenant=true
+func (m *Model) TenantField() (*Field, bool) {
+	for _, fd := range m.Fields {
+		if fd.Tenant {
+			return fd, true
+		}
+	}
+	return nil, false
+}
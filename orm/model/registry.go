@@ -78,6 +78,12 @@ func (r *registry) parseModel(val any) (*Model, error) {
 			Offset:  fdType.Offset,
 			Index:   i,
 		}
+		if tags[tagKeyPrimaryKey] == "true" {
+			f.PrimaryKey = true
+		}
+		if tags[tagKeyTenant] == "true" {
+			f.Tenant = true
+		}
 		fds[fdType.Name] = f
 		colMap[colName] = f
 		fields = append(fields, f)
@@ -0,0 +1,20 @@
+package orm
+
+type OrderBy struct {
+	col   string
+	order string
+}
+
+func Asc(col string) OrderBy {
+	return OrderBy{
+		col:   col,
+		order: "ASC",
+	}
+}
+
+func Desc(col string) OrderBy {
+	return OrderBy{
+		col:   col,
+		order: "DESC",
+	}
+}
@@ -0,0 +1,128 @@
+// Package ormtest helps to test the code using orm without a real database.
+// It runs the tests against in-memory SQLite, and provides fixtures and factories
+// to prepare the data
+package ormtest
+
+import (
+	"WebFrame/orm"
+	"WebFrame/orm/model"
+	"context"
+	"database/sql"
+	"fmt"
+	_ "github.com/mattn/go-sqlite3"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var dbSeq atomic.Int64
+
+// NewDB opens an in-memory SQLite database only visible to t,
+// and creates the tables of models. It is closed when t finishes.
+// The SQLite3 dialect is used, and opts may override the others, such as the registry
+func NewDB(t testing.TB, models []any, opts ...orm.DBOption) *orm.DB {
+	t.Helper()
+	dsn := fmt.Sprintf("file:ormtest_%d?mode=memory&cache=shared", dbSeq.Add(1))
+	db, err := orm.Open("sqlite3", dsn, append([]orm.DBOption{orm.DBWithDialect(orm.SQLite3)}, opts...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+	CreateTables(t, db, models...)
+	return db
+}
+
+// CreateTables creates the tables of models if they do not exist,
+// models are pointers to the structs such as &User{}
+func CreateTables(t testing.TB, db *orm.DB, models ...any) {
+	t.Helper()
+	for _, m := range models {
+		meta, err := db.Registry().Get(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = orm.RawQuery[any](db, createTableSQL(meta)).Exec(context.Background()).Err(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func createTableSQL(m *model.Model) string {
+	pk, _ := m.PrimaryKey()
+	var sb strings.Builder
+	sb.WriteString("CREATE TABLE IF NOT EXISTS `")
+	sb.WriteString(m.TableName)
+	sb.WriteString("`(")
+	for i, fd := range m.Fields {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteByte('`')
+		sb.WriteString(fd.ColName)
+		sb.WriteString("` ")
+		sb.WriteString(columnType(fd.Type))
+		if fd == pk {
+			sb.WriteString(" PRIMARY KEY")
+		}
+	}
+	sb.WriteByte(')')
+	return sb.String()
+}
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	nullTimeType  = reflect.TypeOf(sql.NullTime{})
+	nullFloatType = reflect.TypeOf(sql.NullFloat64{})
+	bytesType     = reflect.TypeOf([]byte{})
+	integerTypes  = []reflect.Type{
+		reflect.TypeOf(sql.NullInt64{}), reflect.TypeOf(sql.NullInt32{}),
+		reflect.TypeOf(sql.NullInt16{}), reflect.TypeOf(sql.NullByte{}), reflect.TypeOf(sql.NullBool{}),
+	}
+)
+
+// columnType maps the Go type to the type affinity of SQLite
+func columnType(typ reflect.Type) string {
+	if typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	switch typ {
+	case timeType, nullTimeType:
+		return "DATETIME"
+	case nullFloatType:
+		return "REAL"
+	case bytesType:
+		return "BLOB"
+	}
+	for _, it := range integerTypes {
+		if typ == it {
+			return "INTEGER"
+		}
+	}
+	switch typ.Kind() {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "INTEGER"
+	case reflect.Float32, reflect.Float64:
+		return "REAL"
+	default:
+		return "TEXT"
+	}
+}
+
+// BeginTx begins a transaction which is rolled back when t finishes,
+// so that the changes of t are invisible to the other tests
+func BeginTx(t testing.TB, db *orm.DB) *orm.Tx {
+	t.Helper()
+	tx, err := db.BeginTx(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = tx.Rollback()
+	})
+	return tx
+}
@@ -0,0 +1,48 @@
+package ormtest
+
+import (
+	"WebFrame/orm"
+	"context"
+	"sync/atomic"
+	"testing"
+)
+
+// Factory builds the values of T for tests
+type Factory[T any] struct {
+	build func(seq int) *T
+	seq   atomic.Int64
+}
+
+// NewFactory creates a Factory, build returns the default value,
+// and seq starts from 1 and increases every time, it helps to make unique fields
+func NewFactory[T any](build func(seq int) *T) *Factory[T] {
+	return &Factory[T]{build: build}
+}
+
+// Build returns a new value, overrides modify the default value in order
+func (f *Factory[T]) Build(overrides ...func(val *T)) *T {
+	val := f.build(int(f.seq.Add(1)))
+	for _, o := range overrides {
+		o(val)
+	}
+	return val
+}
+
+// Create builds a value and inserts it into sess
+func (f *Factory[T]) Create(t testing.TB, sess orm.Session, overrides ...func(val *T)) *T {
+	t.Helper()
+	return f.CreateN(t, sess, 1, overrides...)[0]
+}
+
+// CreateN builds n values and inserts them into sess
+func (f *Factory[T]) CreateN(t testing.TB, sess orm.Session, n int, overrides ...func(val *T)) []*T {
+	t.Helper()
+	vals := make([]*T, 0, n)
+	for i := 0; i < n; i++ {
+		vals = append(vals, f.Build(overrides...))
+	}
+	if err := orm.NewInserter[T](sess).Values(vals...).Exec(context.Background()).Err(); err != nil {
+		t.Fatal(err)
+	}
+	return vals
+}
@@ -0,0 +1,77 @@
+package ormtest
+
+import (
+	"WebFrame/orm"
+	"context"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"os"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// LoadFixtures inserts the rows in files into sess, pass *orm.Tx to roll them back with the test.
+// The files are YAML or JSON, which map the table to its rows,
+// and each row maps the column to the value:
+//
+//	user:
+//	  - id: 1
+//	    name: Tom
+//
+// The tables are inserted in the order they appear in the file
+func LoadFixtures(t testing.TB, sess orm.Session, files ...string) {
+	t.Helper()
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = loadFixture(sess, data); err != nil {
+			t.Fatalf("ormtest: fixture %s: %v", file, err)
+		}
+	}
+}
+
+func loadFixture(sess orm.Session, data []byte) error {
+	// JSON is valid YAML, and yaml.Node keeps the order of the tables
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	if len(doc.Content) == 0 {
+		return nil
+	}
+	tables := doc.Content[0]
+	if tables.Kind != yaml.MappingNode {
+		return fmt.Errorf("the fixture should map the table to its rows")
+	}
+	for i := 0; i < len(tables.Content); i += 2 {
+		tbl := tables.Content[i].Value
+		var rows []map[string]any
+		if err := tables.Content[i+1].Decode(&rows); err != nil {
+			return fmt.Errorf("table %s: %w", tbl, err)
+		}
+		for _, row := range rows {
+			if err := insertRow(sess, tbl, row); err != nil {
+				return fmt.Errorf("table %s: %w", tbl, err)
+			}
+		}
+	}
+	return nil
+}
+
+func insertRow(sess orm.Session, tbl string, row map[string]any) error {
+	cols := make([]string, 0, len(row))
+	for col := range row {
+		cols = append(cols, col)
+	}
+	sort.Strings(cols)
+	args := make([]any, 0, len(cols))
+	for _, col := range cols {
+		args = append(args, row[col])
+	}
+	query := fmt.Sprintf("INSERT INTO `%s`(`%s`) VALUES(%s);", tbl, strings.Join(cols, "`,`"),
+		strings.TrimSuffix(strings.Repeat("?,", len(cols)), ","))
+	return orm.RawQuery[any](sess, query, args...).Exec(context.Background()).Err()
+}
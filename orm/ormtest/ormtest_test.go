@@ -0,0 +1,75 @@
+package ormtest
+
+import (
+	"WebFrame/orm"
+	"WebFrame/orm/internal/errs"
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+type User struct {
+	Id        int64
+	Name      string
+	Age       int8
+	CreatedAt time.Time
+}
+
+type Order struct {
+	Id     int64
+	UserId int64
+	Amount float64
+}
+
+func TestLoadFixtures(t *testing.T) {
+	db := NewDB(t, []any{&User{}, &Order{}})
+	tx := BeginTx(t, db)
+	LoadFixtures(t, tx, "testdata/users.yaml", "testdata/users.json")
+
+	ctx := context.Background()
+	users, err := orm.NewSelector[User](tx).Select(orm.C("Id"), orm.C("Name"), orm.C("Age")).GetMulti(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []*User{
+		{Id: 1, Name: "Tom", Age: 18},
+		{Id: 2, Name: "Jerry", Age: 20},
+		{Id: 3, Name: "Spike", Age: 30},
+	}, users)
+	order, err := orm.NewSelector[Order](tx).Get(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, &Order{Id: 1, UserId: 1, Amount: 9.9}, order)
+}
+
+func TestBeginTx(t *testing.T) {
+	db := NewDB(t, []any{&User{}})
+	factory := NewFactory(func(seq int) *User {
+		return &User{Id: int64(seq), Name: fmt.Sprintf("user_%d", seq)}
+	})
+	t.Run("insert", func(t *testing.T) {
+		tx := BeginTx(t, db)
+		factory.CreateN(t, tx, 3)
+	})
+	// 子测试结束之后回滚
+	_, err := orm.NewSelector[User](db).Get(context.Background())
+	assert.Equal(t, errs.ErrNoRows, err)
+}
+
+func TestFactory(t *testing.T) {
+	db := NewDB(t, []any{&User{}})
+	tx := BeginTx(t, db)
+	now := time.UnixMilli(1000).UTC()
+	factory := NewFactory(func(seq int) *User {
+		return &User{Id: int64(seq), Name: fmt.Sprintf("user_%d", seq), Age: 18, CreatedAt: now}
+	})
+	assert.Equal(t, &User{Id: 1, Name: "user_1", Age: 18, CreatedAt: now}, factory.Build())
+	u := factory.Create(t, tx, func(val *User) {
+		val.Age = 30
+	})
+	assert.Equal(t, &User{Id: 2, Name: "user_2", Age: 30, CreatedAt: now}, u)
+
+	got, err := orm.NewSelector[User](tx).Where(orm.C("Id").EQ(2)).Get(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, u, got)
+}
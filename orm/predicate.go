@@ -3,12 +3,20 @@ package orm
 type op string
 
 const (
-	opEQ  = "="
-	opLT  = "<"
-	opGT  = ">"
-	opAND = "AND"
-	opOR  = "OR"
-	opNOT = "NOT"
+	opEQ   = "="
+	opNEQ  = "!="
+	opLT   = "<"
+	opLTE  = "<="
+	opGT   = ">"
+	opGTE  = ">="
+	opLike = "LIKE"
+	opIn   = "IN"
+	opAND  = "AND"
+	opOR   = "OR"
+	opNOT  = "NOT"
+
+	opAdd   = "+"
+	opMulti = "*"
 )
 
 func (o op) String() string {
@@ -18,6 +18,7 @@ func (r *RawQuerier[T]) Exec(ctx context.Context) Result {
 	return exec(ctx, r.sess, r.core, &QueryContext{
 		Builder: r,
 		Type:    "RAW",
+		Model:   modelOf[T](r.core),
 	})
 }
 
@@ -33,8 +34,14 @@ func (r *RawQuerier[T]) Get(ctx context.Context) (*T, error) {
 }
 
 func (r *RawQuerier[T]) GetMulti(ctx context.Context) ([]*T, error) {
-	// TODO implement me
-	panic("implement me")
+	res := getMulti[T](ctx, r.core, r.sess, &QueryContext{
+		Builder: r,
+		Type:    "RAW",
+	})
+	if res.Res != nil {
+		return res.Res.([]*T), res.Err
+	}
+	return nil, res.Err
 }
 
 func (r *RawQuerier[T]) Build() (*Query, error) {
@@ -0,0 +1,163 @@
+package orm
+
+import (
+	"WebFrame/orm/internal/errs"
+	"WebFrame/orm/model"
+	"context"
+	"errors"
+)
+
+// Repository wraps the common CRUD operations of one model on top of
+// Selector, Inserter and Deleter.
+// The model must have a primary key, see model.Model.PrimaryKey
+type Repository[T any] struct {
+	sess session
+	core
+}
+
+// Page is the result of Repository.List
+type Page[T any] struct {
+	// Total is the number of rows matching the predicates, ignoring pagination
+	Total int64
+	Items []*T
+}
+
+// NewRepository creates a Repository, sess can be either *DB or *Tx
+func NewRepository[T any](sess session) *Repository[T] {
+	return &Repository[T]{
+		sess: sess,
+		core: sess.getCore(),
+	}
+}
+
+func (r *Repository[T]) primaryKey() (*model.Model, *model.Field, error) {
+	m, err := r.r.Get(new(T))
+	if err != nil {
+		return nil, nil, err
+	}
+	pk, err := m.PrimaryKey()
+	if err != nil {
+		return nil, nil, err
+	}
+	return m, pk, nil
+}
+
+// FindByID returns the row whose primary key equals id
+func (r *Repository[T]) FindByID(ctx context.Context, id any) (*T, error) {
+	_, pk, err := r.primaryKey()
+	if err != nil {
+		return nil, err
+	}
+	return NewSelector[T](r.sess).Where(C(pk.GoName).EQ(id)).Get(ctx)
+}
+
+// Count returns the number of rows matching ps
+func (r *Repository[T]) Count(ctx context.Context, ps ...Predicate) (int64, error) {
+	_, pk, err := r.primaryKey()
+	if err != nil {
+		return 0, err
+	}
+	s := NewSelector[T](r.sess).Select(Count(pk.GoName)).Where(ps...)
+	res := getValue[int64](ctx, r.core, r.sess, &QueryContext{
+		Builder: s,
+		Type:    "SELECT",
+		Model:   modelOf[T](r.core),
+	})
+	if res.Err != nil {
+		return 0, res.Err
+	}
+	return res.Res.(int64), nil
+}
+
+// List returns the rows matching ps in the given page, page starts from 1.
+// If size <= 0, all matching rows are returned
+func (r *Repository[T]) List(ctx context.Context, page int, size int, ps ...Predicate) (*Page[T], error) {
+	total, err := r.Count(ctx, ps...)
+	if err != nil {
+		return nil, err
+	}
+	res := &Page[T]{Total: total}
+	if total == 0 {
+		res.Items = []*T{}
+		return res, nil
+	}
+	s := NewSelector[T](r.sess).Where(ps...)
+	if size > 0 {
+		if page < 1 {
+			page = 1
+		}
+		s = s.Limit(size).Offset((page - 1) * size)
+	}
+	res.Items, err = s.GetMulti(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// Exists checks whether there is any row matching ps
+func (r *Repository[T]) Exists(ctx context.Context, ps ...Predicate) (bool, error) {
+	s := NewSelector[T](r.sess).Select(Raw("1")).Where(ps...).Limit(1)
+	res := getValue[int64](ctx, r.core, r.sess, &QueryContext{
+		Builder: s,
+		Type:    "SELECT",
+		Model:   modelOf[T](r.core),
+	})
+	if errors.Is(res.Err, errs.ErrNoRows) {
+		return false, nil
+	}
+	if res.Err != nil {
+		return false, res.Err
+	}
+	return true, nil
+}
+
+// Insert inserts vals
+func (r *Repository[T]) Insert(ctx context.Context, vals ...*T) Result {
+	return NewInserter[T](r.sess).Values(vals...).Exec(ctx)
+}
+
+// Save inserts val, or updates all the other columns if the primary key already exists
+func (r *Repository[T]) Save(ctx context.Context, val *T) Result {
+	m, pk, err := r.primaryKey()
+	if err != nil {
+		return Result{err: err}
+	}
+	assigns := make([]Assignable, 0, len(m.Fields)-1)
+	for _, fd := range m.Fields {
+		if fd == pk {
+			continue
+		}
+		assigns = append(assigns, C(fd.GoName))
+	}
+	i := NewInserter[T](r.sess).Values(val)
+	if len(assigns) > 0 {
+		i = i.OnDuplicateKey().ConflictColumns(pk.GoName).Update(assigns...)
+	}
+	return i.Exec(ctx)
+}
+
+// DeleteByID deletes the row whose primary key equals id
+func (r *Repository[T]) DeleteByID(ctx context.Context, id any) Result {
+	_, pk, err := r.primaryKey()
+	if err != nil {
+		return Result{err: err}
+	}
+	return NewDeleter[T](r.sess).Where(C(pk.GoName).EQ(id)).Exec(ctx)
+}
+
+// FirstOrCreate returns the first row matching ps.
+// If there is none, val is inserted and returned, and created will be true
+func (r *Repository[T]) FirstOrCreate(ctx context.Context, val *T, ps ...Predicate) (res *T, created bool, err error) {
+	res, err = NewSelector[T](r.sess).Where(ps...).Limit(1).Get(ctx)
+	if err == nil {
+		return res, false, nil
+	}
+	if !errors.Is(err, errs.ErrNoRows) {
+		return nil, false, err
+	}
+	if err = r.Insert(ctx, val).Err(); err != nil {
+		return nil, false, err
+	}
+	return val, true, nil
+}
@@ -0,0 +1,98 @@
+package orm
+
+import (
+	"WebFrame/orm/internal/errs"
+	"context"
+	"database/sql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestRepository(t *testing.T) {
+	db := testModelDB(t, "repository")
+	ctx := context.Background()
+	repo := NewRepository[TestModel](db)
+
+	res := repo.Insert(ctx,
+		&TestModel{Id: 1, FirstName: "Deng", Age: 18, LastName: &sql.NullString{String: "Ming", Valid: true}},
+		&TestModel{Id: 2, FirstName: "Da", Age: 19, LastName: &sql.NullString{String: "Ming", Valid: true}},
+		&TestModel{Id: 3, FirstName: "Xiao", Age: 20, LastName: &sql.NullString{String: "Ming", Valid: true}},
+	)
+	require.NoError(t, res.Err())
+
+	tm, err := repo.FindByID(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, "Da", tm.FirstName)
+
+	_, err = repo.FindByID(ctx, 100)
+	assert.Equal(t, errs.ErrNoRows, err)
+
+	cnt, err := repo.Count(ctx, C("Age").GT(18))
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), cnt)
+
+	page, err := repo.List(ctx, 2, 2)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), page.Total)
+	assert.Equal(t, 1, len(page.Items))
+
+	page, err = repo.List(ctx, 1, 10, C("Age").GT(30))
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), page.Total)
+	assert.Equal(t, 0, len(page.Items))
+
+	ok, err := repo.Exists(ctx, C("FirstName").EQ("Deng"))
+	require.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = repo.Exists(ctx, C("FirstName").EQ("Nobody"))
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	// update by primary key
+	res = repo.Save(ctx, &TestModel{Id: 1, FirstName: "Deng", Age: 28, LastName: &sql.NullString{String: "Ming", Valid: true}})
+	require.NoError(t, res.Err())
+	tm, err = repo.FindByID(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, int8(28), tm.Age)
+
+	tm, created, err := repo.FirstOrCreate(ctx, &TestModel{Id: 4, FirstName: "Deng"}, C("FirstName").EQ("Deng"))
+	require.NoError(t, err)
+	assert.False(t, created)
+	assert.Equal(t, int64(1), tm.Id)
+	tm, created, err = repo.FirstOrCreate(ctx, &TestModel{Id: 4, FirstName: "New"}, C("FirstName").EQ("New"))
+	require.NoError(t, err)
+	assert.True(t, created)
+	assert.Equal(t, int64(4), tm.Id)
+
+	affected, err := repo.DeleteByID(ctx, 4).RowsAffected()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), affected)
+}
+
+func TestRepository_Tx(t *testing.T) {
+	db := testModelDB(t, "repository")
+	ctx := context.Background()
+	tx, err := db.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	repo := NewRepository[TestModel](tx)
+	res := repo.Insert(ctx, &TestModel{Id: 10, FirstName: "Tx"})
+	require.NoError(t, res.Err())
+	ok, err := repo.Exists(ctx, C("Id").EQ(10))
+	require.NoError(t, err)
+	assert.True(t, ok)
+	require.NoError(t, tx.Rollback())
+
+	ok, err = NewRepository[TestModel](db).Exists(ctx, C("Id").EQ(10))
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestRepository_NoPrimaryKey(t *testing.T) {
+	type NoKey struct {
+		Name string
+	}
+	db := memoryDB(t)
+	_, err := NewRepository[NoKey](db).FindByID(context.Background(), 1)
+	assert.Equal(t, errs.NewErrNoPrimaryKey("no_key"), err)
+}
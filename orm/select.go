@@ -13,11 +13,28 @@ type Selector[T any] struct {
 	table   string
 	columns []Selectable
 	groupBy []Column
+	orderBy []OrderBy
 	offset  int
 	limit   int
+	lock    rowLock
 	sess    session
 }
 
+// rowLock is the locking clause, such as FOR UPDATE SKIP LOCKED
+type rowLock struct {
+	// mode is FOR UPDATE or FOR SHARE, empty means no lock
+	mode string
+	// option is SKIP LOCKED or NOWAIT
+	option string
+}
+
+const (
+	lockForUpdate  = "FOR UPDATE"
+	lockForShare   = "FOR SHARE"
+	lockSkipLocked = "SKIP LOCKED"
+	lockNoWait     = "NOWAIT"
+)
+
 func (s *Selector[T]) Select(cols ...Selectable) *Selector[T] {
 	s.columns = cols
 	return s
@@ -29,17 +46,28 @@ func (s *Selector[T]) From(tbl string) *Selector[T] {
 }
 
 func (s *Selector[T]) Build() (*Query, error) {
+	return s.buildQuery(func() error {
+		if err := s.build(); err != nil {
+			return err
+		}
+		return s.sb.WriteByte(';')
+	})
+}
+
+// build writes the statement without the trailing ';',
+// so that it can be a part of a SetQuery
+func (s *Selector[T]) build() error {
 	var (
 		t   T
 		err error
 	)
 	s.model, err = s.r.Get(&t)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	s.sb.WriteString("SELECT ")
 	if err = s.buildColumns(); err != nil {
-		return nil, err
+		return err
 	}
 	s.sb.WriteString(" FROM ")
 	if s.table == "" {
@@ -55,7 +83,7 @@ func (s *Selector[T]) Build() (*Query, error) {
 			p = p.And(s.where[i])
 		}
 		if err = s.buildExpression(p); err != nil {
-			return nil, err
+			return err
 		}
 	}
 	if len(s.groupBy) > 0 {
@@ -65,7 +93,7 @@ func (s *Selector[T]) Build() (*Query, error) {
 				s.sb.WriteByte(',')
 			}
 			if err = s.buildColumn(c, false); err != nil {
-				return nil, err
+				return err
 			}
 		}
 
@@ -73,7 +101,13 @@ func (s *Selector[T]) Build() (*Query, error) {
 	if len(s.having) > 0 {
 		s.sb.WriteString(" HAVING ")
 		if err = s.buildPredicates(s.having); err != nil {
-			return nil, err
+			return err
+		}
+	}
+	if len(s.orderBy) > 0 {
+		s.sb.WriteString(" ORDER BY ")
+		if err = s.buildOrderBy(s.orderBy); err != nil {
+			return err
 		}
 	}
 	if s.limit > 0 {
@@ -85,12 +119,15 @@ func (s *Selector[T]) Build() (*Query, error) {
 		s.sb.WriteString(" OFFSET ?")
 		s.addArgs(s.offset)
 	}
-	s.sb.WriteByte(';')
-	return &Query{
-		SQL:  s.sb.String(),
-		Args: s.args,
-	}, nil
-
+	if s.lock.mode != "" {
+		if _, ok := s.sess.(*Tx); !ok {
+			return errs.ErrLockOutsideTx
+		}
+		if err = s.dialect.buildLock(&s.builder, s.lock); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (s *Selector[T]) buildPredicates(ps []Predicate) error {
@@ -124,6 +161,30 @@ func (s *Selector[T]) buildColumns() error {
 			if len(val.args) != 0 {
 				s.addArgs(val.args...)
 			}
+		case MathExpr:
+			if err := s.buildExpression(val); err != nil {
+				return err
+			}
+		case aliasExpr:
+			if err := s.buildExpression(val.expr); err != nil {
+				return err
+			}
+			s.buildAs(val.alias)
+		case CaseExpr:
+			if err := s.buildExpression(val); err != nil {
+				return err
+			}
+			s.buildAs(val.alias)
+		case FuncExpr:
+			if err := s.buildExpression(val); err != nil {
+				return err
+			}
+			s.buildAs(val.alias)
+		case WindowExpr:
+			if err := s.buildExpression(val); err != nil {
+				return err
+			}
+			s.buildAs(val.alias)
 		default:
 			return errs.NewErrUnsupportedSelectable(c)
 		}
@@ -161,58 +222,28 @@ func (s *Selector[T]) buildColumn(c Column, useAlias bool) error {
 	return nil
 }
 
-func (s *Selector[T]) buildExpression(e Expression) error {
-	if e == nil {
-		return nil
-	}
-	switch exp := e.(type) {
-	case Column:
-		return s.buildColumn(exp, true)
-	case value:
-		s.sb.WriteByte('?')
-		s.args = append(s.args, exp.val)
-	case Predicate:
-		_, lp := exp.left.(Predicate)
-		if lp {
-			s.sb.WriteByte('(')
-		}
-		if err := s.buildExpression(exp.left); err != nil {
-			return err
-		}
-		if lp {
-			s.sb.WriteByte(')')
-		}
-		s.sb.WriteByte(' ')
-		s.sb.WriteString(exp.op.String())
-		s.sb.WriteByte(' ')
-		_, rp := exp.right.(Predicate)
-		if rp {
-			s.sb.WriteByte('(')
-		}
-		if err := s.buildExpression(exp.right); err != nil {
-			return err
-		}
-		if rp {
-			s.sb.WriteByte(')')
-		}
-	case Aggregate:
-		return s.buildAggregate(exp, false)
-	case RawExpr:
-		s.sb.WriteString(exp.raw)
-		if len(exp.args) != 0 {
-			s.addArgs(exp.args...)
-		}
-	default:
-		return errs.NewErrUnsupportedExpressionType(exp)
-	}
-	return nil
-}
-
 func (s *Selector[T]) Where(ps ...Predicate) *Selector[T] {
 	s.where = ps
 	return s
 }
 
+func (s *Selector[T]) addWhere(ps ...Predicate) {
+	// do not modify the slice passed by the user
+	s.where = append(s.where[:len(s.where):len(s.where)], ps...)
+}
+
+// scoped returns a copy of s narrowed by ps
+func (s *Selector[T]) scoped(ps ...Predicate) QueryBuilder {
+	return s.withWhere(ps...)
+}
+
+func (s *Selector[T]) withWhere(ps ...Predicate) *Selector[T] {
+	cp := *s
+	cp.builder = s.builder.clone()
+	cp.addWhere(ps...)
+	return &cp
+}
+
 func (s *Selector[T]) Get(ctx context.Context) (*T, error) {
 	res := get[T](ctx, s.core, s.sess, &QueryContext{
 		Builder: s,
@@ -224,33 +255,15 @@ func (s *Selector[T]) Get(ctx context.Context) (*T, error) {
 	return nil, res.Err
 }
 
-// todo wait for refactor
 func (s *Selector[T]) GetMulti(ctx context.Context) ([]*T, error) {
-	q, err := s.Build()
-	if err != nil {
-		return nil, err
-	}
-	rows, err := s.sess.queryContext(ctx, q.SQL, q.Args...)
-	if err != nil {
-		return nil, err
-	}
-	tps := make([]*T, 2)
-	count := 0
-	for rows.Next() {
-		// construct
-		tp := new(T)
-		meta, er := s.r.Get(tp)
-		if er != nil {
-			return nil, er
-		}
-		val := s.valCreator(tp, meta)
-		er = val.SetColumns(rows)
-		if er != nil && count <= cap(tps) {
-			tps[count] = tp
-			count++
-		}
+	res := getMulti[T](ctx, s.core, s.sess, &QueryContext{
+		Builder: s,
+		Type:    "SELECT",
+	})
+	if res.Res != nil {
+		return res.Res.([]*T), res.Err
 	}
-	return tps, nil
+	return nil, res.Err
 }
 
 func (s *Selector[T]) addArgs(args ...any) {
@@ -280,6 +293,11 @@ func (s *Selector[T]) Having(ps ...Predicate) *Selector[T] {
 	return s
 }
 
+func (s *Selector[T]) OrderBy(obs ...OrderBy) *Selector[T] {
+	s.orderBy = obs
+	return s
+}
+
 func (s *Selector[T]) Offset(offset int) *Selector[T] {
 	s.offset = offset
 	return s
@@ -290,6 +308,55 @@ func (s *Selector[T]) Limit(limit int) *Selector[T] {
 	return s
 }
 
+// ForUpdate locks the selected rows, it can only be used with Tx
+func (s *Selector[T]) ForUpdate() *Selector[T] {
+	s.lock.mode = lockForUpdate
+	return s
+}
+
+// ForShare locks the selected rows in share mode, it can only be used with Tx
+func (s *Selector[T]) ForShare() *Selector[T] {
+	s.lock.mode = lockForShare
+	return s
+}
+
+// SkipLocked skips the rows locked by others,
+// it implies ForUpdate if neither ForUpdate nor ForShare is called
+func (s *Selector[T]) SkipLocked() *Selector[T] {
+	if s.lock.mode == "" {
+		s.lock.mode = lockForUpdate
+	}
+	s.lock.option = lockSkipLocked
+	return s
+}
+
+// NoWait fails immediately if the rows are locked by others,
+// it implies ForUpdate if neither ForUpdate nor ForShare is called
+func (s *Selector[T]) NoWait() *Selector[T] {
+	if s.lock.mode == "" {
+		s.lock.mode = lockForUpdate
+	}
+	s.lock.option = lockNoWait
+	return s
+}
+
+// Union combines the selectors with UNION, see SetQuery
+func (s *Selector[T]) Union(others ...*Selector[T]) *SetQuery[T] {
+	return newSetQuery(s).Union(others...)
+}
+
+func (s *Selector[T]) UnionAll(others ...*Selector[T]) *SetQuery[T] {
+	return newSetQuery(s).UnionAll(others...)
+}
+
+func (s *Selector[T]) Intersect(others ...*Selector[T]) *SetQuery[T] {
+	return newSetQuery(s).Intersect(others...)
+}
+
+func (s *Selector[T]) Except(others ...*Selector[T]) *SetQuery[T] {
+	return newSetQuery(s).Except(others...)
+}
+
 func NewSelector[T any](sess session) *Selector[T] {
 	c := sess.getCore()
 	return &Selector[T]{
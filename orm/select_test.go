@@ -8,6 +8,7 @@ import (
 	"github.com/DATA-DOG/go-sqlmock"
 	_ "github.com/mattn/go-sqlite3"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"testing"
 )
 
@@ -19,6 +20,21 @@ func memoryDB(t *testing.T, opts ...DBOption) *DB {
 	return orm
 }
 
+// testModelDB opens the in-memory SQLite database name with the test_model table,
+// the databases of different names do not share data
+func testModelDB(t testing.TB, name string, opts ...DBOption) *DB {
+	opts = append([]DBOption{DBWithDialect(SQLite3)}, opts...)
+	db, err := Open("sqlite3", "file:"+name+".db?cache=shared&mode=memory", opts...)
+	require.NoError(t, err)
+	_, err = db.db.Exec("CREATE TABLE IF NOT EXISTS `test_model`(" +
+		"`id` INTEGER PRIMARY KEY, `first_name` TEXT, `age` INTEGER, `last_name` TEXT)")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+	return db
+}
+
 func TestSelector_OffsetLimit(t *testing.T) {
 	db := memoryDB(t)
 	testCases := []struct {
@@ -174,6 +190,97 @@ func TestSelector_GroupBy(t *testing.T) {
 	}
 }
 
+func TestSelector_OrderBy(t *testing.T) {
+	db := memoryDB(t)
+	testCases := []struct {
+		name      string
+		q         QueryBuilder
+		wantQuery *Query
+		wantErr   error
+	}{
+		{
+			name: "multiple",
+			q:    NewSelector[TestModel](db).OrderBy(Asc("Age"), Desc("Id")).Limit(10),
+			wantQuery: &Query{
+				SQL:  "SELECT * FROM `test_model` ORDER BY `age` ASC,`id` DESC LIMIT ?;",
+				Args: []any{10},
+			},
+		},
+		{
+			name:    "invalid column",
+			q:       NewSelector[TestModel](db).OrderBy(Asc("Invalid")),
+			wantErr: errs.NewErrUnknownField("Invalid"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			query, err := tc.q.Build()
+			assert.Equal(t, tc.wantErr, err)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, tc.wantQuery, query)
+		})
+	}
+}
+
+func TestSelector_Operators(t *testing.T) {
+	db := memoryDB(t)
+	testCases := []struct {
+		name      string
+		q         QueryBuilder
+		wantQuery *Query
+	}{
+		{
+			name: "compare",
+			q:    NewSelector[TestModel](db).Where(C("Age").GTE(18), C("Age").LTE(30), C("Id").NEQ(1)),
+			wantQuery: &Query{
+				SQL:  "SELECT * FROM `test_model` WHERE ((`age` >= ?) AND (`age` <= ?)) AND (`id` != ?);",
+				Args: []any{18, 30, 1},
+			},
+		},
+		{
+			name: "like",
+			q:    NewSelector[TestModel](db).Where(C("FirstName").Like("%Tom%")),
+			wantQuery: &Query{
+				SQL:  "SELECT * FROM `test_model` WHERE `first_name` LIKE ?;",
+				Args: []any{"%Tom%"},
+			},
+		},
+		{
+			name: "in",
+			q:    NewSelector[TestModel](db).Where(C("Id").In(1, 2, 3)),
+			wantQuery: &Query{
+				SQL:  "SELECT * FROM `test_model` WHERE `id` IN (?,?,?);",
+				Args: []any{1, 2, 3},
+			},
+		},
+		{
+			name: "typed column",
+			q: NewSelector[TestModel](db).Where(TC[int8]("Age").GT(18),
+				TC[int64]("Id").In(1, 2)),
+			wantQuery: &Query{
+				SQL:  "SELECT * FROM `test_model` WHERE (`age` > ?) AND (`id` IN (?,?));",
+				Args: []any{int8(18), int64(1), int64(2)},
+			},
+		},
+		{
+			name: "empty in",
+			q:    NewSelector[TestModel](db).Where(C("Id").In()),
+			wantQuery: &Query{
+				SQL: "SELECT * FROM `test_model` WHERE `id` IN (NULL);",
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			query, err := tc.q.Build()
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantQuery, query)
+		})
+	}
+}
+
 func TestSelector_Build(t *testing.T) {
 	db := memoryDB(t)
 	testCases := []struct {
@@ -372,3 +479,71 @@ func TestSelector_Get(t *testing.T) {
 		})
 	}
 }
+
+func TestSelector_Lock(t *testing.T) {
+	db := memoryDB(t, DBWithDialect(MySQL))
+	tx, err := db.BeginTx(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	sqliteDB := memoryDB(t, DBWithDialect(SQLite3))
+	sqliteTx, err := sqliteDB.BeginTx(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = sqliteTx.Rollback() }()
+
+	testCases := []struct {
+		name      string
+		q         QueryBuilder
+		wantQuery *Query
+		wantErr   error
+	}{
+		{
+			name: "for update",
+			q:    NewSelector[TestModel](tx).Where(C("Id").EQ(1)).ForUpdate(),
+			wantQuery: &Query{
+				SQL:  "SELECT * FROM `test_model` WHERE `id` = ? FOR UPDATE;",
+				Args: []any{1},
+			},
+		},
+		{
+			name: "for share nowait",
+			q:    NewSelector[TestModel](tx).ForShare().NoWait(),
+			wantQuery: &Query{
+				SQL: "SELECT * FROM `test_model` FOR SHARE NOWAIT;",
+			},
+		},
+		{
+			// SKIP LOCKED 默认使用 FOR UPDATE
+			name: "skip locked",
+			q:    NewSelector[TestModel](tx).Limit(10).SkipLocked(),
+			wantQuery: &Query{
+				SQL:  "SELECT * FROM `test_model` LIMIT ? FOR UPDATE SKIP LOCKED;",
+				Args: []any{10},
+			},
+		},
+		{
+			name:    "outside tx",
+			q:       NewSelector[TestModel](db).ForUpdate(),
+			wantErr: errs.ErrLockOutsideTx,
+		},
+		{
+			name:    "sqlite3",
+			q:       NewSelector[TestModel](sqliteTx).ForUpdate(),
+			wantErr: errs.NewErrUnsupportedLock("sqlite3", "FOR UPDATE"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			query, err := tc.q.Build()
+			assert.Equal(t, tc.wantErr, err)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, tc.wantQuery, query)
+		})
+	}
+}
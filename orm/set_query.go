@@ -0,0 +1,175 @@
+package orm
+
+import (
+	"WebFrame/orm/internal/errs"
+	"context"
+)
+
+const (
+	setOpUnion     = "UNION"
+	setOpUnionAll  = "UNION ALL"
+	setOpIntersect = "INTERSECT"
+	setOpExcept    = "EXCEPT"
+)
+
+// SetQuery combines several Selector with UNION, UNION ALL, INTERSECT and EXCEPT.
+// ORDER BY, LIMIT and OFFSET of SetQuery apply to the whole result,
+// the selectors can not use OrderBy, Limit, Offset or row locks, Build returns an error for them
+type SetQuery[T any] struct {
+	builder
+	first *Selector[T]
+	parts []setPart[T]
+
+	orderBy []OrderBy
+	offset  int
+	limit   int
+	sess    session
+}
+
+type setPart[T any] struct {
+	op string
+	s  *Selector[T]
+}
+
+func newSetQuery[T any](first *Selector[T]) *SetQuery[T] {
+	c := first.sess.getCore()
+	return &SetQuery[T]{
+		first: first,
+		sess:  first.sess,
+		builder: builder{
+			core:    c,
+			dialect: c.dialect,
+			quoter:  c.dialect.quoter(),
+		},
+	}
+}
+
+func (q *SetQuery[T]) combine(op string, others []*Selector[T]) *SetQuery[T] {
+	for _, s := range others {
+		q.parts = append(q.parts, setPart[T]{op: op, s: s})
+	}
+	return q
+}
+
+func (q *SetQuery[T]) Union(others ...*Selector[T]) *SetQuery[T] {
+	return q.combine(setOpUnion, others)
+}
+
+func (q *SetQuery[T]) UnionAll(others ...*Selector[T]) *SetQuery[T] {
+	return q.combine(setOpUnionAll, others)
+}
+
+func (q *SetQuery[T]) Intersect(others ...*Selector[T]) *SetQuery[T] {
+	return q.combine(setOpIntersect, others)
+}
+
+func (q *SetQuery[T]) Except(others ...*Selector[T]) *SetQuery[T] {
+	return q.combine(setOpExcept, others)
+}
+
+func (q *SetQuery[T]) OrderBy(obs ...OrderBy) *SetQuery[T] {
+	q.orderBy = obs
+	return q
+}
+
+func (q *SetQuery[T]) Offset(offset int) *SetQuery[T] {
+	q.offset = offset
+	return q
+}
+
+func (q *SetQuery[T]) Limit(limit int) *SetQuery[T] {
+	q.limit = limit
+	return q
+}
+
+// scoped returns a copy of q whose selectors are all narrowed by ps
+func (q *SetQuery[T]) scoped(ps ...Predicate) QueryBuilder {
+	cp := *q
+	cp.builder = q.builder.clone()
+	cp.first = q.first.withWhere(ps...)
+	cp.parts = make([]setPart[T], 0, len(q.parts))
+	for _, p := range q.parts {
+		cp.parts = append(cp.parts, setPart[T]{op: p.op, s: p.s.withWhere(ps...)})
+	}
+	return &cp
+}
+
+func (q *SetQuery[T]) Build() (*Query, error) {
+	return q.buildQuery(q.build)
+}
+
+func (q *SetQuery[T]) build() error {
+	var (
+		t   T
+		err error
+	)
+	q.model, err = q.r.Get(&t)
+	if err != nil {
+		return err
+	}
+	if err = q.buildPart(q.first); err != nil {
+		return err
+	}
+	for _, p := range q.parts {
+		q.sb.WriteByte(' ')
+		q.sb.WriteString(p.op)
+		q.sb.WriteByte(' ')
+		if err = q.buildPart(p.s); err != nil {
+			return err
+		}
+	}
+	if len(q.orderBy) > 0 {
+		q.sb.WriteString(" ORDER BY ")
+		if err = q.buildOrderBy(q.orderBy); err != nil {
+			return err
+		}
+	}
+	if q.limit > 0 {
+		q.sb.WriteString(" LIMIT ?")
+		q.addArgs(q.limit)
+	}
+	if q.offset > 0 {
+		q.sb.WriteString(" OFFSET ?")
+		q.addArgs(q.offset)
+	}
+	return q.sb.WriteByte(';')
+}
+
+func (q *SetQuery[T]) buildPart(s *Selector[T]) error {
+	// a part with these clauses would need parentheses, which are not supported by all the databases,
+	// for example SQLite
+	if len(s.orderBy) > 0 || s.limit > 0 || s.offset > 0 || s.lock.mode != "" {
+		return errs.ErrInvalidSetQueryPart
+	}
+	s.reset()
+	if err := s.build(); err != nil {
+		return err
+	}
+	q.sb.WriteString(s.sb.String())
+	if len(s.args) > 0 {
+		q.addArgs(s.args...)
+	}
+	return nil
+}
+
+func (q *SetQuery[T]) Get(ctx context.Context) (*T, error) {
+	res := get[T](ctx, q.core, q.sess, &QueryContext{
+		Builder: q,
+		Type:    "SELECT",
+	})
+	if res.Res != nil {
+		return res.Res.(*T), res.Err
+	}
+	return nil, res.Err
+}
+
+func (q *SetQuery[T]) GetMulti(ctx context.Context) ([]*T, error) {
+	res := getMulti[T](ctx, q.core, q.sess, &QueryContext{
+		Builder: q,
+		Type:    "SELECT",
+	})
+	if res.Res != nil {
+		return res.Res.([]*T), res.Err
+	}
+	return nil, res.Err
+}
@@ -0,0 +1,130 @@
+package orm
+
+import (
+	"WebFrame/orm/internal/errs"
+	"context"
+	"database/sql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestSetQuery_Build(t *testing.T) {
+	db := memoryDB(t)
+	testCases := []struct {
+		name      string
+		q         QueryBuilder
+		wantQuery *Query
+		wantErr   error
+	}{
+		{
+			name: "union",
+			q: NewSelector[TestModel](db).Where(C("Age").LT(18)).
+				Union(NewSelector[TestModel](db).Where(C("Age").GT(60))),
+			wantQuery: &Query{
+				SQL:  "SELECT * FROM `test_model` WHERE `age` < ? UNION SELECT * FROM `test_model` WHERE `age` > ?;",
+				Args: []any{18, 60},
+			},
+		},
+		{
+			name: "mixed",
+			q: NewSelector[TestModel](db).Where(C("Age").LT(18)).
+				UnionAll(NewSelector[TestModel](db).Where(C("Age").GT(60))).
+				Intersect(NewSelector[TestModel](db).Where(C("FirstName").EQ("Tom"))).
+				Except(NewSelector[TestModel](db).Where(C("Id").EQ(1))),
+			wantQuery: &Query{
+				SQL: "SELECT * FROM `test_model` WHERE `age` < ? " +
+					"UNION ALL SELECT * FROM `test_model` WHERE `age` > ? " +
+					"INTERSECT SELECT * FROM `test_model` WHERE `first_name` = ? " +
+					"EXCEPT SELECT * FROM `test_model` WHERE `id` = ?;",
+				Args: []any{18, 60, "Tom", 1},
+			},
+		},
+		{
+			name: "order by limit offset",
+			q: NewSelector[TestModel](db).Where(C("Age").LT(18)).
+				Union(NewSelector[TestModel](db).Where(C("Age").GT(60)),
+					NewSelector[TestModel](db).Where(C("Id").EQ(3))).
+				OrderBy(Desc("Age"), Asc("Id")).Limit(10).Offset(20),
+			wantQuery: &Query{
+				SQL: "SELECT * FROM `test_model` WHERE `age` < ? " +
+					"UNION SELECT * FROM `test_model` WHERE `age` > ? " +
+					"UNION SELECT * FROM `test_model` WHERE `id` = ? " +
+					"ORDER BY `age` DESC,`id` ASC LIMIT ? OFFSET ?;",
+				Args: []any{18, 60, 3, 10, 20},
+			},
+		},
+		{
+			name: "invalid column in part",
+			q: NewSelector[TestModel](db).
+				Union(NewSelector[TestModel](db).Where(C("Invalid").GT(60))),
+			wantErr: errs.NewErrUnknownField("Invalid"),
+		},
+		{
+			name: "order by in part",
+			q: NewSelector[TestModel](db).
+				Union(NewSelector[TestModel](db).OrderBy(Asc("Age"))),
+			wantErr: errs.ErrInvalidSetQueryPart,
+		},
+		{
+			name: "limit in first part",
+			q: NewSelector[TestModel](db).Limit(10).
+				Union(NewSelector[TestModel](db)),
+			wantErr: errs.ErrInvalidSetQueryPart,
+		},
+		{
+			name: "offset in part",
+			q: NewSelector[TestModel](db).
+				Union(NewSelector[TestModel](db).Offset(10)),
+			wantErr: errs.ErrInvalidSetQueryPart,
+		},
+		{
+			name: "lock in part",
+			q: NewSelector[TestModel](db).
+				Union(NewSelector[TestModel](db).ForUpdate()),
+			wantErr: errs.ErrInvalidSetQueryPart,
+		},
+		{
+			name: "invalid order by",
+			q: NewSelector[TestModel](db).
+				Union(NewSelector[TestModel](db)).OrderBy(Asc("Invalid")),
+			wantErr: errs.NewErrUnknownField("Invalid"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			query, err := tc.q.Build()
+			assert.Equal(t, tc.wantErr, err)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, tc.wantQuery, query)
+		})
+	}
+}
+
+func TestSetQuery_GetMulti(t *testing.T) {
+	db := testModelDB(t, "repository")
+	ctx := context.Background()
+	_, err := db.db.Exec("DELETE FROM `test_model`")
+	require.NoError(t, err)
+	res := NewInserter[TestModel](db).Values(
+		&TestModel{Id: 1, FirstName: "A", Age: 10, LastName: &sql.NullString{}},
+		&TestModel{Id: 2, FirstName: "B", Age: 30, LastName: &sql.NullString{}},
+		&TestModel{Id: 3, FirstName: "C", Age: 70, LastName: &sql.NullString{}},
+	).Exec(ctx)
+	require.NoError(t, res.Err())
+
+	tms, err := NewSelector[TestModel](db).Where(C("Age").LT(18)).
+		Union(NewSelector[TestModel](db).Where(C("Age").GT(60))).
+		OrderBy(Desc("Id")).GetMulti(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(tms))
+	assert.Equal(t, int64(3), tms[0].Id)
+	assert.Equal(t, int64(1), tms[1].Id)
+
+	tm, err := NewSelector[TestModel](db).
+		Except(NewSelector[TestModel](db).Where(C("Age").LT(60))).Get(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), tm.Id)
+}
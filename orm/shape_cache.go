@@ -0,0 +1,75 @@
+package orm
+
+import (
+	"sync"
+)
+
+// shapeCache caches the SQL by the shape of the query.
+// The shape is the exact sequence of the fragments written by the builder,
+// which is stored as a trie, so different SQL never share an entry.
+// Looking up a shape only walks the trie, and the SQL is not written at all
+type shapeCache struct {
+	mu   sync.RWMutex
+	root *shapeNode
+	// size is the max number of SQL, the cache is cleared when it is full
+	size int
+	cnt  int
+}
+
+type shapeNode struct {
+	// most nodes have only one child, so a slice is faster than a map
+	children []shapeEdge
+	sql      string
+	// end means sql is set, it is the SQL of the fragments from root to this node
+	end bool
+}
+
+type shapeEdge struct {
+	frag string
+	node *shapeNode
+}
+
+func newShapeCache(size int) *shapeCache {
+	return &shapeCache{root: &shapeNode{}, size: size}
+}
+
+// child returns the child of frag, nil if it does not exist
+func (n *shapeNode) child(frag string) *shapeNode {
+	for _, e := range n.children {
+		if e.frag == frag {
+			return e.node
+		}
+	}
+	return nil
+}
+
+// add caches sql as the SQL of frags
+func (c *shapeCache) add(frags []string, sql string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.cnt >= c.size {
+		c.root = &shapeNode{}
+		c.cnt = 0
+	}
+	n := c.root
+	for _, frag := range frags {
+		next := n.child(frag)
+		if next == nil {
+			next = &shapeNode{}
+			n.children = append(n.children, shapeEdge{frag: frag, node: next})
+		}
+		n = next
+	}
+	if !n.end {
+		n.end = true
+		n.sql = sql
+		c.cnt++
+	}
+}
+
+// Len returns the number of SQL cached
+func (c *shapeCache) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.cnt
+}
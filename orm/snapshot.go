@@ -0,0 +1,164 @@
+package orm
+
+import (
+	"WebFrame/orm/internal/errs"
+	"context"
+)
+
+// snapshotKeyBatch is the max number of keys read by one query,
+// it is below the limit of placeholders of the databases, such as 999 of old SQLite
+const snapshotKeyBatch = 500
+
+// snapshotter is implemented by UPDATE and DELETE,
+// it returns the predicates selecting the rows to be changed
+type snapshotter interface {
+	snapshotWhere() []Predicate
+}
+
+// keySnapshotter is implemented by INSERT,
+// it returns the primary keys of the values
+type keySnapshotter interface {
+	snapshotKeys() ([]any, error)
+}
+
+// Snapshot reads the current values of the rows the query is going to change,
+// in the session of the query so that the uncommitted changes are visible.
+// For UPDATE and DELETE, they are the rows matching WHERE,
+// and the queries without WHERE are refused since they change the whole table.
+// For INSERT, they are the rows having the same primary keys as the values,
+// which only exist when the query is an upsert.
+// Each row maps the column name to the value
+func (qc *QueryContext) Snapshot(ctx context.Context) ([]map[string]any, error) {
+	if qc.Model == nil {
+		return nil, errs.NewErrUnsupportedSnapshot(qc.Builder)
+	}
+	switch s := qc.Builder.(type) {
+	case snapshotter:
+		ps := s.snapshotWhere()
+		if len(ps) == 0 {
+			return nil, errs.ErrSnapshotWithoutWhere
+		}
+		return qc.snapshot(ctx, ps)
+	case keySnapshotter:
+		keys, err := s.snapshotKeys()
+		if err != nil {
+			return nil, err
+		}
+		return qc.SnapshotByKeys(ctx, keys...)
+	default:
+		return nil, errs.NewErrUnsupportedSnapshot(qc.Builder)
+	}
+}
+
+// SnapshotByKeys reads the current values of the rows whose primary keys are in keys,
+// in the session of the query
+func (qc *QueryContext) SnapshotByKeys(ctx context.Context, keys ...any) ([]map[string]any, error) {
+	if qc.Model == nil {
+		return nil, errs.NewErrUnsupportedSnapshot(qc.Builder)
+	}
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	pk, err := qc.Model.PrimaryKey()
+	if err != nil {
+		return nil, err
+	}
+	// IN is split into batches, so that the number of placeholders is limited
+	res := make([]map[string]any, 0, len(keys))
+	for start := 0; start < len(keys); start += snapshotKeyBatch {
+		end := min(start+snapshotKeyBatch, len(keys))
+		rows, er := qc.snapshot(ctx, []Predicate{C(pk.GoName).In(keys[start:end]...)})
+		if er != nil {
+			return nil, er
+		}
+		res = append(res, rows...)
+	}
+	return res, nil
+}
+
+func (qc *QueryContext) snapshot(ctx context.Context, ps []Predicate) ([]map[string]any, error) {
+	// nothing is sent to the database under dry-run
+	if IsDryRun(ctx) {
+		return nil, nil
+	}
+	c := qc.sess.getCore()
+	b := builder{
+		core:    c,
+		dialect: c.dialect,
+		quoter:  c.dialect.quoter(),
+		model:   qc.Model,
+	}
+	b.sb.WriteString("SELECT * FROM ")
+	b.quote(qc.Model.TableName)
+	if len(ps) > 0 {
+		b.sb.WriteString(" WHERE ")
+		if err := b.buildPredicates(ps); err != nil {
+			return nil, err
+		}
+	}
+	b.sb.WriteByte(';')
+	rows, err := qc.sess.queryContext(ctx, b.sb.String(), b.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	res := make([]map[string]any, 0, 4)
+	for rows.Next() {
+		vals := make([]any, len(cols))
+		ptrs := make([]any, len(cols))
+		for i := range vals {
+			ptrs[i] = &vals[i]
+		}
+		if err = rows.Scan(ptrs...); err != nil {
+			return nil, err
+		}
+		row := make(map[string]any, len(cols))
+		for i, col := range cols {
+			// text columns are returned as []byte by some drivers
+			if bs, ok := vals[i].([]byte); ok {
+				row[col] = string(bs)
+				continue
+			}
+			row[col] = vals[i]
+		}
+		res = append(res, row)
+	}
+	return res, rows.Err()
+}
+
+func (d *Deleter[T]) snapshotWhere() []Predicate {
+	return d.where
+}
+
+func (u *Updater[T]) snapshotWhere() []Predicate {
+	return u.where
+}
+
+func (i *Inserter[T]) snapshotKeys() ([]any, error) {
+	if len(i.values) == 0 {
+		return nil, errs.ErrInsertZeroRow
+	}
+	m, err := i.r.Get(new(T))
+	if err != nil {
+		return nil, err
+	}
+	pk, err := m.PrimaryKey()
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]any, 0, len(i.values))
+	for _, val := range i.values {
+		key, er := i.valCreator(val, m).Field(pk.GoName)
+		if er != nil {
+			return nil, er
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
@@ -0,0 +1,107 @@
+package orm
+
+import (
+	"WebFrame/orm/internal/errs"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestQueryContext_Snapshot(t *testing.T) {
+	db := testModelDB(t, "repository")
+	ctx := context.Background()
+	tx, err := db.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	defer func() { _ = tx.Rollback() }()
+	require.NoError(t, NewInserter[TestModel](tx).Values(
+		&TestModel{Id: 1, FirstName: "Tom", Age: 18},
+		&TestModel{Id: 2, FirstName: "Jerry", Age: 20},
+	).Exec(ctx).Err())
+
+	// 超过占位符上限的主键会分批读取
+	manyKeys := make([]any, 0, 1500)
+	manyVals := make([]*TestModel, 0, 1500)
+	for i := 1; i <= 1500; i++ {
+		manyKeys = append(manyKeys, i)
+		manyVals = append(manyVals, &TestModel{Id: int64(i)})
+	}
+
+	testCases := []struct {
+		name    string
+		qc      *QueryContext
+		keys    []any
+		wantIds []any
+		wantErr error
+	}{
+		{
+			name:    "delete",
+			qc:      &QueryContext{Builder: NewDeleter[TestModel](tx).Where(C("Age").GT(18))},
+			wantIds: []any{int64(2)},
+		},
+		{
+			name:    "update",
+			qc:      &QueryContext{Builder: NewUpdater[TestModel](tx).Set(Assign("Age", 1)).Where(C("Id").LT(10))},
+			wantIds: []any{int64(1), int64(2)},
+		},
+		{
+			name:    "update without where",
+			qc:      &QueryContext{Builder: NewUpdater[TestModel](tx).Set(Assign("Age", 1))},
+			wantErr: errs.ErrSnapshotWithoutWhere,
+		},
+		{
+			name:    "delete without where",
+			qc:      &QueryContext{Builder: NewDeleter[TestModel](tx)},
+			wantErr: errs.ErrSnapshotWithoutWhere,
+		},
+		{
+			// 只有 upsert 会读到已有的数据
+			name:    "insert",
+			qc:      &QueryContext{Builder: NewInserter[TestModel](tx).Values(&TestModel{Id: 1}, &TestModel{Id: 3})},
+			wantIds: []any{int64(1)},
+		},
+		{
+			name:    "by keys",
+			qc:      &QueryContext{Builder: NewDeleter[TestModel](tx)},
+			keys:    []any{2, 3},
+			wantIds: []any{int64(2)},
+		},
+		{
+			name:    "many keys",
+			qc:      &QueryContext{Builder: NewDeleter[TestModel](tx)},
+			keys:    manyKeys,
+			wantIds: []any{int64(1), int64(2)},
+		},
+		{
+			name:    "insert many values",
+			qc:      &QueryContext{Builder: NewInserter[TestModel](tx).Values(manyVals...)},
+			wantIds: []any{int64(1), int64(2)},
+		},
+		{
+			name:    "selector",
+			qc:      &QueryContext{Builder: NewSelector[TestModel](tx)},
+			wantErr: errs.NewErrUnsupportedSnapshot(NewSelector[TestModel](tx)),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.qc.Model = modelOf[TestModel](tx.getCore())
+			tc.qc.sess = tx
+			var rows []map[string]any
+			if tc.keys != nil {
+				rows, err = tc.qc.SnapshotByKeys(ctx, tc.keys...)
+			} else {
+				rows, err = tc.qc.Snapshot(ctx)
+			}
+			assert.Equal(t, tc.wantErr, err)
+			if err != nil {
+				return
+			}
+			ids := make([]any, 0, len(rows))
+			for _, row := range rows {
+				ids = append(ids, row["id"])
+			}
+			assert.Equal(t, tc.wantIds, ids)
+		})
+	}
+}
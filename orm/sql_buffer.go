@@ -0,0 +1,68 @@
+package orm
+
+import (
+	"strings"
+)
+
+// byteStrs avoids allocating when a byte is used as a fragment
+var byteStrs = func() (res [256]string) {
+	for i := range res {
+		res[i] = string([]byte{byte(i)})
+	}
+	return
+}()
+
+// sqlBuffer is where the builders write the SQL to.
+// In walk mode the SQL is not written but matched against the shape cache,
+// in record mode the SQL is written and the fragments are recorded to be added to the shape cache
+type sqlBuffer struct {
+	sb      strings.Builder
+	walking bool
+	// node is where the fragments written so far lead to, nil if they are not cached
+	node      *shapeNode
+	recording bool
+	frags     []string
+}
+
+func (b *sqlBuffer) WriteString(s string) (int, error) {
+	if b.walking {
+		if b.node != nil {
+			b.node = b.node.child(s)
+		}
+		return len(s), nil
+	}
+	if b.recording {
+		b.frags = append(b.frags, s)
+	}
+	return b.sb.WriteString(s)
+}
+
+func (b *sqlBuffer) WriteByte(c byte) error {
+	_, err := b.WriteString(byteStrs[c])
+	return err
+}
+
+func (b *sqlBuffer) String() string {
+	return b.sb.String()
+}
+
+// walkMode resets the buffer and starts matching the fragments from root
+func (b *sqlBuffer) walkMode(root *shapeNode) {
+	b.reset()
+	b.walking = true
+	b.node = root
+}
+
+// recordMode resets the buffer and starts recording the fragments
+func (b *sqlBuffer) recordMode() {
+	b.reset()
+	b.recording = true
+	b.frags = b.frags[:0]
+}
+
+func (b *sqlBuffer) reset() {
+	b.sb.Reset()
+	b.walking = false
+	b.node = nil
+	b.recording = false
+}
@@ -0,0 +1,120 @@
+package orm
+
+import (
+	"context"
+	"database/sql"
+	"github.com/hashicorp/golang-lru/simplelru"
+	"sync"
+)
+
+// stmtCache caches the prepared statements by SQL text.
+// A statement may be evicted while someone is still using it,
+// so it is reference counted and closed when the last user releases it
+type stmtCache struct {
+	mu    sync.Mutex
+	db    *sql.DB
+	cache *simplelru.LRU
+}
+
+type cachedStmt struct {
+	stmt    *sql.Stmt
+	refs    int
+	evicted bool
+}
+
+func newStmtCache(db *sql.DB, size int) (*stmtCache, error) {
+	c := &stmtCache{db: db}
+	cache, err := simplelru.NewLRU(size, c.onEvict)
+	if err != nil {
+		return nil, err
+	}
+	c.cache = cache
+	return c, nil
+}
+
+// onEvict is invoked by simplelru with c.mu held
+func (c *stmtCache) onEvict(_ any, val any) {
+	cs := val.(*cachedStmt)
+	cs.evicted = true
+	if cs.refs == 0 {
+		_ = cs.stmt.Close()
+	}
+}
+
+// get returns the statement of query, preparing it if it is not cached.
+// The caller must call release after using it
+func (c *stmtCache) get(ctx context.Context, query string) (*cachedStmt, error) {
+	c.mu.Lock()
+	if val, ok := c.cache.Get(query); ok {
+		cs := val.(*cachedStmt)
+		cs.refs++
+		c.mu.Unlock()
+		return cs, nil
+	}
+	c.mu.Unlock()
+
+	// prepare without holding the lock, it needs a round trip to the database
+	stmt, err := c.db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if val, ok := c.cache.Get(query); ok {
+		// prepared by others at the same time
+		_ = stmt.Close()
+		cs := val.(*cachedStmt)
+		cs.refs++
+		return cs, nil
+	}
+	cs := &cachedStmt{stmt: stmt, refs: 1}
+	c.cache.Add(query, cs)
+	return cs, nil
+}
+
+func (c *stmtCache) release(cs *cachedStmt) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cs.refs--
+	if cs.evicted && cs.refs == 0 {
+		_ = cs.stmt.Close()
+	}
+}
+
+func (c *stmtCache) queryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	cs, err := c.get(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	// closing the statement is deferred by database/sql until the rows are closed
+	defer c.release(cs)
+	return cs.stmt.QueryContext(ctx, args...)
+}
+
+func (c *stmtCache) execContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	cs, err := c.get(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer c.release(cs)
+	return cs.stmt.ExecContext(ctx, args...)
+}
+
+// txStmt returns the transaction-specific statement of query,
+// it is closed when the transaction ends, see Tx.stmt
+func (c *stmtCache) txStmt(ctx context.Context, tx *sql.Tx, query string) (*sql.Stmt, error) {
+	cs, err := c.get(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer c.release(cs)
+	return tx.StmtContext(ctx, cs.stmt), nil
+}
+
+// close closes all the statements which are not in use,
+// the others will be closed when they are released
+func (c *stmtCache) close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cache.Purge()
+}
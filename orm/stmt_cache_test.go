@@ -0,0 +1,98 @@
+package orm
+
+import (
+	"context"
+	"database/sql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestStmtCache_Evict(t *testing.T) {
+	db := testModelDB(t, "stmt_cache", DBWithStmtCache(1))
+	ctx := context.Background()
+
+	a, err := db.stmts.get(ctx, "SELECT 1")
+	require.NoError(t, err)
+	db.stmts.release(a)
+	// cached
+	res, err := db.stmts.get(ctx, "SELECT 1")
+	require.NoError(t, err)
+	assert.Same(t, a, res)
+
+	// a is in use, evicting it does not close it
+	b, err := db.stmts.get(ctx, "SELECT 2")
+	require.NoError(t, err)
+	assert.True(t, a.evicted)
+	_, err = a.stmt.ExecContext(ctx)
+	require.NoError(t, err)
+	db.stmts.release(a)
+	_, err = a.stmt.ExecContext(ctx)
+	assert.EqualError(t, err, "sql: statement is closed")
+
+	db.stmts.release(b)
+	require.NoError(t, db.Close())
+	_, err = b.stmt.ExecContext(ctx)
+	assert.EqualError(t, err, "sql: statement is closed")
+}
+
+func TestStmtCache_Session(t *testing.T) {
+	db := testModelDB(t, "stmt_cache", DBWithStmtCache(8))
+	ctx := context.Background()
+	repo := NewRepository[TestModel](db)
+	require.NoError(t, repo.Save(ctx, &TestModel{Id: 1, FirstName: "Deng", LastName: &sql.NullString{}}).Err())
+	require.NoError(t, repo.Save(ctx, &TestModel{Id: 1, FirstName: "Da", LastName: &sql.NullString{}}).Err())
+	tm, err := repo.FindByID(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "Da", tm.FirstName)
+
+	err = db.DoTx(ctx, func(ctx context.Context, tx *Tx) error {
+		txRepo := NewRepository[TestModel](tx)
+		if er := txRepo.Insert(ctx, &TestModel{Id: 2, FirstName: "Tx", LastName: &sql.NullString{}}).Err(); er != nil {
+			return er
+		}
+		tm, er := txRepo.FindByID(ctx, 2)
+		if er != nil {
+			return er
+		}
+		assert.Equal(t, "Tx", tm.FirstName)
+		// the statement is bound to the transaction only once
+		for i := 0; i < 3; i++ {
+			if _, er = txRepo.FindByID(ctx, 2); er != nil {
+				return er
+			}
+		}
+		assert.Len(t, tx.stmts, 2)
+		return nil
+	}, nil)
+	require.NoError(t, err)
+	cnt, err := repo.Count(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), cnt)
+}
+
+func BenchmarkSelector_Get(b *testing.B) {
+	ctx := context.Background()
+	benchmarks := []struct {
+		name string
+		opts []DBOption
+	}{
+		{name: "no cache"},
+		{name: "stmt cache", opts: []DBOption{DBWithStmtCache(16)}},
+	}
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			db := testModelDB(b, "stmt_cache", bm.opts...)
+			res := NewInserter[TestModel](db).Values(&TestModel{Id: 1, FirstName: "Deng", LastName: &sql.NullString{}}).
+				OnDuplicateKey().ConflictColumns("Id").Update(C("FirstName")).Exec(ctx)
+			require.NoError(b, res.Err())
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				_, err := NewSelector[TestModel](db).Where(C("Id").EQ(1)).Get(ctx)
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package orm
 import (
 	"context"
 	"database/sql"
+	"sync"
 )
 
 var _ session = &Tx{}
@@ -18,6 +19,12 @@ type Tx struct {
 	tx   *sql.Tx
 	db   *DB
 	done bool
+
+	// stmts caches the transaction-specific statements by SQL text,
+	// database/sql keeps every statement created by the transaction until it ends,
+	// so each SQL is bound to the transaction only once
+	mu    sync.Mutex
+	stmts map[string]*sql.Stmt
 }
 
 func (t *Tx) getCore() core {
@@ -25,13 +32,46 @@ func (t *Tx) getCore() core {
 }
 
 func (t *Tx) queryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	if t.db.stmts != nil {
+		stmt, err := t.stmt(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+		return stmt.QueryContext(ctx, args...)
+	}
 	return t.tx.QueryContext(ctx, query, args...)
 }
 
 func (t *Tx) execContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	if t.db.stmts != nil {
+		stmt, err := t.stmt(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+		return stmt.ExecContext(ctx, args...)
+	}
 	return t.tx.ExecContext(ctx, query, args...)
 }
 
+// stmt returns the cached statement of query in the transaction,
+// the statements are closed by database/sql when the transaction ends
+func (t *Tx) stmt(ctx context.Context, query string) (*sql.Stmt, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if stmt, ok := t.stmts[query]; ok {
+		return stmt, nil
+	}
+	stmt, err := t.db.stmts.txStmt(ctx, t.tx, query)
+	if err != nil {
+		return nil, err
+	}
+	if t.stmts == nil {
+		t.stmts = make(map[string]*sql.Stmt, 4)
+	}
+	t.stmts[query] = stmt
+	return stmt, nil
+}
+
 func (t *Tx) Commit() error {
 	t.done = true
 	return t.tx.Commit()
@@ -0,0 +1,45 @@
+package orm
+
+// TypedColumn is a Column whose values must be of type V,
+// it is generated by cmd/ormgen so that passing a value of the wrong type is a compile error.
+// Use the embedded Column in Select, GroupBy and so on
+type TypedColumn[V any] struct {
+	Column
+}
+
+// TC creates a TypedColumn of the field name
+func TC[V any](name string) TypedColumn[V] {
+	return TypedColumn[V]{Column: C(name)}
+}
+
+func (c TypedColumn[V]) EQ(arg V) Predicate {
+	return c.Column.EQ(arg)
+}
+
+func (c TypedColumn[V]) NEQ(arg V) Predicate {
+	return c.Column.NEQ(arg)
+}
+
+func (c TypedColumn[V]) LT(arg V) Predicate {
+	return c.Column.LT(arg)
+}
+
+func (c TypedColumn[V]) LTE(arg V) Predicate {
+	return c.Column.LTE(arg)
+}
+
+func (c TypedColumn[V]) GT(arg V) Predicate {
+	return c.Column.GT(arg)
+}
+
+func (c TypedColumn[V]) GTE(arg V) Predicate {
+	return c.Column.GTE(arg)
+}
+
+func (c TypedColumn[V]) In(vals ...V) Predicate {
+	args := make([]any, 0, len(vals))
+	for _, val := range vals {
+		args = append(args, val)
+	}
+	return c.Column.In(args...)
+}
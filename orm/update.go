@@ -0,0 +1,121 @@
+package orm
+
+import (
+	"WebFrame/orm/internal/errs"
+	"context"
+)
+
+type Updater[T any] struct {
+	builder
+	val     *T
+	assigns []Assignable
+	where   []Predicate
+
+	sess session
+}
+
+func NewUpdater[T any](sess session) *Updater[T] {
+	c := sess.getCore()
+	return &Updater[T]{
+		sess: sess,
+		builder: builder{
+			core:    c,
+			dialect: c.dialect,
+			quoter:  c.dialect.quoter(),
+		},
+	}
+}
+
+// Update sets the value providing the values of the columns passed to Set
+func (u *Updater[T]) Update(val *T) *Updater[T] {
+	u.val = val
+	return u
+}
+
+// Set selects the columns to be updated.
+// Column takes its value from the value passed to Update,
+// and Assignment sets the column to its own value
+func (u *Updater[T]) Set(assigns ...Assignable) *Updater[T] {
+	u.assigns = assigns
+	return u
+}
+
+func (u *Updater[T]) Where(ps ...Predicate) *Updater[T] {
+	u.where = ps
+	return u
+}
+
+// scoped returns a copy of u narrowed by ps
+func (u *Updater[T]) scoped(ps ...Predicate) QueryBuilder {
+	cp := *u
+	cp.builder = u.builder.clone()
+	// do not modify the slice passed by the user
+	cp.where = append(u.where[:len(u.where):len(u.where)], ps...)
+	return &cp
+}
+
+func (u *Updater[T]) Build() (*Query, error) {
+	return u.buildQuery(u.build)
+}
+
+func (u *Updater[T]) build() error {
+	if len(u.assigns) == 0 {
+		return errs.ErrNoUpdatedColumns
+	}
+	var (
+		t   T
+		err error
+	)
+	u.model, err = u.r.Get(&t)
+	if err != nil {
+		return err
+	}
+	u.sb.WriteString("UPDATE ")
+	u.quote(u.model.TableName)
+	u.sb.WriteString(" SET ")
+	for idx, a := range u.assigns {
+		if idx > 0 {
+			u.sb.WriteByte(',')
+		}
+		switch assign := a.(type) {
+		case Column:
+			if u.val == nil {
+				return errs.ErrNoUpdatedValue
+			}
+			if err = u.buildColumn(assign.name); err != nil {
+				return err
+			}
+			val, er := u.valCreator(u.val, u.model).Field(assign.name)
+			if er != nil {
+				return er
+			}
+			u.sb.WriteString("=?")
+			u.addArgs(val)
+		case Assignment:
+			if err = u.buildColumn(assign.col); err != nil {
+				return err
+			}
+			u.sb.WriteByte('=')
+			if err = u.buildExpression(assign.val); err != nil {
+				return err
+			}
+		default:
+			return errs.NewErrUnsupportedAssignableType(a)
+		}
+	}
+	if len(u.where) > 0 {
+		u.sb.WriteString(" WHERE ")
+		if err = u.buildPredicates(u.where); err != nil {
+			return err
+		}
+	}
+	return u.sb.WriteByte(';')
+}
+
+func (u *Updater[T]) Exec(ctx context.Context) Result {
+	return exec(ctx, u.sess, u.core, &QueryContext{
+		Builder: u,
+		Type:    "UPDATE",
+		Model:   modelOf[T](u.core),
+	})
+}
@@ -0,0 +1,64 @@
+package orm
+
+import (
+	"WebFrame/orm/internal/errs"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestUpdater_Build(t *testing.T) {
+	db := memoryDB(t)
+	testCases := []struct {
+		name      string
+		builder   QueryBuilder
+		wantErr   error
+		wantQuery *Query
+	}{
+		{
+			name:    "no columns",
+			builder: NewUpdater[TestModel](db).Update(&TestModel{}),
+			wantErr: errs.ErrNoUpdatedColumns,
+		},
+		{
+			name:    "no value",
+			builder: NewUpdater[TestModel](db).Set(C("Age")),
+			wantErr: errs.ErrNoUpdatedValue,
+		},
+		{
+			name: "columns",
+			builder: NewUpdater[TestModel](db).Update(&TestModel{FirstName: "Tom", Age: 18}).
+				Set(C("FirstName"), C("Age")),
+			wantQuery: &Query{
+				SQL:  "UPDATE `test_model` SET `first_name`=?,`age`=?;",
+				Args: []any{"Tom", int8(18)},
+			},
+		},
+		{
+			name: "assignment",
+			builder: NewUpdater[TestModel](db).
+				Set(Assign("Age", C("Age").Add(1)), Assign("FirstName", "Tom")).
+				Where(C("Id").EQ(1)),
+			wantQuery: &Query{
+				SQL:  "UPDATE `test_model` SET `age`=`age` + ?,`first_name`=? WHERE `id` = ?;",
+				Args: []any{1, "Tom", 1},
+			},
+		},
+		{
+			name:    "unknown field",
+			builder: NewUpdater[TestModel](db).Set(Assign("Invalid", 1)),
+			wantErr: errs.NewErrUnknownField("Invalid"),
+		},
+	}
+
+	for _, tc := range testCases {
+		c := tc
+		t.Run(c.name, func(t *testing.T) {
+			query, err := c.builder.Build()
+			assert.Equal(t, c.wantErr, err)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, tc.wantQuery, query)
+		})
+	}
+}
@@ -48,6 +48,14 @@ func (c *Context) FormValue(key string) StringValue {
 	return StringValue{val: c.Req.FormValue(key)}
 }
 
+// QueryValues returns all the query values, such as the input of orm.Filter
+func (c *Context) QueryValues() url.Values {
+	if c.cacheQueryValues == nil {
+		c.cacheQueryValues = c.Req.URL.Query()
+	}
+	return c.cacheQueryValues
+}
+
 func (c *Context) QueryValue(key string) StringValue {
 	if c.cacheQueryValues == nil {
 		c.cacheQueryValues = c.Req.URL.Query()
@@ -82,6 +90,62 @@ func (c *Context) Render(tplName string, data any) error {
 	return err
 }
 
+// Writer returns the ResponseWriter of c.Resp, which writes the response directly without buffering
+func (c *Context) Writer() ResponseWriter {
+	w := newResponseWriter(c.Resp)
+	c.Resp = w
+	return w
+}
+
+// Write writes data to the client directly, so that Context can be used as an io.Writer for streaming.
+// The header is written with RespStatusCode at the first time, and the buffered RespData goes first
+func (c *Context) Write(data []byte) (int, error) {
+	if err := c.writeBuffered(); err != nil {
+		return 0, err
+	}
+	return c.Writer().Write(data)
+}
+
+// Flush sends the data written to the client immediately
+func (c *Context) Flush() {
+	if err := c.writeBuffered(); err != nil {
+		return
+	}
+	c.Writer().Flush()
+}
+
+// writeBuffered writes the header and RespData which are set before streaming
+func (c *Context) writeBuffered() error {
+	w := c.Writer()
+	if !w.Written() && c.RespStatusCode > 0 {
+		w.WriteHeader(c.RespStatusCode)
+	}
+	if len(c.RespData) == 0 {
+		return nil
+	}
+	data := c.RespData
+	c.RespData = nil
+	_, err := w.Write(data)
+	return err
+}
+
+// StatusCode returns the status code of the response,
+// it is the one written if the header has been written, or RespStatusCode, or http.StatusOK by default
+func (c *Context) StatusCode() int {
+	if w := c.Writer(); w.Written() {
+		return w.Status()
+	}
+	if c.RespStatusCode > 0 {
+		return c.RespStatusCode
+	}
+	return http.StatusOK
+}
+
+// BytesWritten returns the size of the response body, including the RespData not flushed yet
+func (c *Context) BytesWritten() int {
+	return c.Writer().Size() + len(c.RespData)
+}
+
 func (c *Context) RespJSON(code int, val any) error {
 	bs, err := json.Marshal(val)
 	if err != nil {
@@ -157,9 +157,9 @@ func (h *StaticResourceHandler) readFileFromData(fileName string) (*fileCacheIte
 }
 
 func (h *StaticResourceHandler) writeItemAsResponse(item *fileCacheItem, w http.ResponseWriter) {
-	w.WriteHeader(http.StatusOK)
 	w.Header().Set("Content-Type", item.contentType)
 	w.Header().Set("Content-Length", fmt.Sprintf("%d", item.fileSize))
+	w.WriteHeader(http.StatusOK)
 	_, _ = w.Write(item.data)
 }
 
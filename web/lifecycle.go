@@ -0,0 +1,179 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+const defaultHookTimeout = 10 * time.Second
+
+// Hook runs when the server starts or shuts down,
+// ctx is canceled when the hook timeout is reached
+type Hook func(ctx context.Context) error
+
+// ServerWithHookTimeout limits the running time of each Hook, d <= 0 means no limit
+func ServerWithHookTimeout(d time.Duration) HTTPServerOption {
+	return func(server *HTTPServer) {
+		server.hookTimeout = d
+	}
+}
+
+// ServerWithDrainPeriod limits the time of waiting for the in-flight requests in Shutdown,
+// the remaining connections are closed forcibly after d.
+// d <= 0 means waiting until the ctx of Shutdown is done
+func ServerWithDrainPeriod(d time.Duration) HTTPServerOption {
+	return func(server *HTTPServer) {
+		server.drainPeriod = d
+	}
+}
+
+// OnStart appends hooks which run in order before the server starts listening,
+// Start fails if any of them fails
+func (s *HTTPServer) OnStart(hooks ...Hook) {
+	s.onStart = append(s.onStart, hooks...)
+}
+
+// OnShutdown appends hooks which run in order after the in-flight requests are drained,
+// such as closing orm.DB. All of them run even if some fail
+func (s *HTTPServer) OnShutdown(hooks ...Hook) {
+	s.onShutdown = append(s.onShutdown, hooks...)
+}
+
+// Start runs the OnStart hooks and serves on addr until Shutdown is called,
+// it returns nil if the server is shut down.
+// addr is ignored if ServerWithListener is used
+func (s *HTTPServer) Start(addr string) error {
+	return s.start(addr, nil, s.srv.Serve)
+}
+
+// Serve is like Start, but serves on l, such as a unix socket listener
+func (s *HTTPServer) Serve(l net.Listener) error {
+	return s.start("", l, s.srv.Serve)
+}
+
+// start runs the OnStart hooks, and then serve on l.
+// If l is nil, the listener of ServerWithListener is used, or it listens on addr
+func (s *HTTPServer) start(addr string, l net.Listener, serve func(l net.Listener) error) error {
+	if err := s.runHooks(context.Background(), s.onStart, true); err != nil {
+		return err
+	}
+	if l == nil {
+		l = s.listener
+	}
+	if l == nil {
+		if addr == "" {
+			addr = ":http"
+		}
+		var err error
+		if l, err = net.Listen("tcp", addr); err != nil {
+			return err
+		}
+	}
+	s.srv.Addr = l.Addr().String()
+	err := serve(l)
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// Shutdown stops accepting new requests, waits for the in-flight ones for the drain period,
+// and then runs the OnShutdown hooks. Only the first call takes effect, the others return the same error
+func (s *HTTPServer) Shutdown(ctx context.Context) error {
+	s.shutdownOnce.Do(func() {
+		s.shutdownErr = s.shutdown(ctx)
+	})
+	return s.shutdownErr
+}
+
+func (s *HTTPServer) shutdown(ctx context.Context) error {
+	drainCtx := ctx
+	if s.drainPeriod > 0 {
+		var cancel context.CancelFunc
+		drainCtx, cancel = context.WithTimeout(ctx, s.drainPeriod)
+		defer cancel()
+	}
+	err := s.srv.Shutdown(drainCtx)
+	if err != nil {
+		// the drain period is over, close the remaining connections
+		err = errors.Join(fmt.Errorf("web: failed to drain requests: %w", err), s.srv.Close())
+	}
+	// the hooks must run even if ctx is done during draining
+	return errors.Join(err, s.runHooks(context.WithoutCancel(ctx), s.onShutdown, false))
+}
+
+// runHooks runs hooks in order, it stops at the first error if failFast
+func (s *HTTPServer) runHooks(ctx context.Context, hooks []Hook, failFast bool) error {
+	var errs []error
+	for i, hook := range hooks {
+		if err := s.runHook(ctx, hook); err != nil {
+			err = fmt.Errorf("web: hook %d failed: %w", i, err)
+			if failFast {
+				return err
+			}
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *HTTPServer) runHook(ctx context.Context, hook Hook) error {
+	if s.hookTimeout <= 0 {
+		return hook(ctx)
+	}
+	ctx, cancel := context.WithTimeout(ctx, s.hookTimeout)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		done <- hook(ctx)
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		// do not wait for the hook ignoring ctx
+		return ctx.Err()
+	}
+}
+
+// Run starts the server on addr, and shuts it down with ctx when one of signals is received,
+// signals are os.Interrupt and syscall.SIGTERM by default.
+// The second signal exits the process immediately
+func (s *HTTPServer) Run(ctx context.Context, addr string, signals ...os.Signal) error {
+	if len(signals) == 0 {
+		signals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+	ch := make(chan os.Signal, 2)
+	signal.Notify(ch, signals...)
+	defer signal.Stop(ch)
+
+	startErr := make(chan error, 1)
+	go func() {
+		startErr <- s.Start(addr)
+	}()
+	select {
+	case err := <-startErr:
+		return err
+	case <-ch:
+	}
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ch:
+			os.Exit(1)
+		case <-done:
+		}
+	}()
+	if err := s.Shutdown(ctx); err != nil {
+		return err
+	}
+	return <-startErr
+}
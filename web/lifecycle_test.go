@@ -0,0 +1,110 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPServer_Lifecycle(t *testing.T) {
+	var events []string
+	hook := func(name string, err error) Hook {
+		return func(ctx context.Context) error {
+			events = append(events, name)
+			return err
+		}
+	}
+	s := NewHTTPServer(ServerWithHookTimeout(50 * time.Millisecond))
+	started := make(chan struct{})
+	s.OnStart(hook("start1", nil), func(ctx context.Context) error {
+		events = append(events, "start2")
+		close(started)
+		return nil
+	})
+	s.OnShutdown(hook("shutdown1", errors.New("mock error")), hook("shutdown2", nil),
+		func(ctx context.Context) error {
+			// 超时
+			<-ctx.Done()
+			return nil
+		})
+
+	startErr := make(chan error, 1)
+	go func() {
+		startErr <- s.Start("127.0.0.1:0")
+	}()
+	<-started
+
+	err := s.Shutdown(context.Background())
+	assert.ErrorContains(t, err, "web: hook 0 failed: mock error")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	// 重复调用返回同样的结果，hook 不会再次执行
+	assert.Equal(t, err, s.Shutdown(context.Background()))
+	assert.NoError(t, <-startErr)
+	assert.Equal(t, []string{"start1", "start2", "shutdown1", "shutdown2"}, events)
+}
+
+func TestHTTPServer_StartHookFailed(t *testing.T) {
+	s := NewHTTPServer()
+	s.OnStart(func(ctx context.Context) error {
+		return errors.New("mock error")
+	})
+	err := s.Start("127.0.0.1:0")
+	assert.EqualError(t, err, "web: hook 0 failed: mock error")
+}
+
+func TestHTTPServer_Run(t *testing.T) {
+	s := NewHTTPServer()
+	var shutdown bool
+	s.OnStart(func(ctx context.Context) error {
+		// 模拟收到信号
+		return syscall.Kill(syscall.Getpid(), syscall.SIGUSR1)
+	})
+	s.OnShutdown(func(ctx context.Context) error {
+		shutdown = true
+		return nil
+	})
+	err := s.Run(context.Background(), "127.0.0.1:0", syscall.SIGUSR1)
+	require.NoError(t, err)
+	assert.True(t, shutdown)
+}
+
+func TestHTTPServer_Drain(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	s := NewHTTPServer(ServerWithListener(l), ServerWithDrainPeriod(time.Second))
+	received := make(chan struct{})
+	s.Get("/slow", func(ctx *Context) {
+		close(received)
+		time.Sleep(100 * time.Millisecond)
+		ctx.RespData = []byte("done")
+	})
+	startErr := make(chan error, 1)
+	go func() {
+		startErr <- s.Start("")
+	}()
+
+	respCh := make(chan string, 1)
+	go func() {
+		resp, er := http.Get("http://" + l.Addr().String() + "/slow")
+		if er != nil {
+			respCh <- er.Error()
+			return
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		respCh <- string(body)
+	}()
+	<-received
+	// 正在处理的请求会被处理完
+	require.NoError(t, s.Shutdown(context.Background()))
+	assert.Equal(t, "done", <-respCh)
+	assert.NoError(t, <-startErr)
+}
@@ -15,6 +15,8 @@ type accessLog struct {
 	Route      string
 	HTTPMethod string `json:"http_method"`
 	Path       string
+	StatusCode int `json:"status_code"`
+	Bytes      int
 }
 
 func (b *MiddlewareBuilder) LogFunc(logFunc func(accessLog string)) *MiddlewareBuilder {
@@ -39,6 +41,8 @@ func (b *MiddlewareBuilder) Build() web.Middleware {
 					Route:      ctx.MatchedRoute,
 					HTTPMethod: ctx.Req.Method,
 					Path:       ctx.Req.URL.Path,
+					StatusCode: ctx.StatusCode(),
+					Bytes:      ctx.BytesWritten(),
 				}
 				val, _ := json.Marshal(l)
 				b.logFunc(string(val))
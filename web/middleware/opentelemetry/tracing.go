@@ -45,7 +45,7 @@ func (b *MiddlewareBuilder) Build() web.Middleware {
 			}
 
 			// 怎么拿到响应的状态呢？比如说用户有没有返回错误，响应码是多少，怎么办？
-			span.SetAttributes(attribute.Int("http.status", ctx.RespStatusCode))
+			span.SetAttributes(attribute.Int("http.status", ctx.StatusCode()))
 		}
 	}
 }
@@ -34,7 +34,7 @@ func (m *MiddlewareBuilder) Build() web.Middleware {
 }
 
 func report(dur time.Duration, ctx *web.Context, vec prometheus.ObserverVec) {
-	status := ctx.RespStatusCode
+	status := ctx.StatusCode()
 	route := "unknown"
 	if ctx.MatchedRoute != "" {
 		route = ctx.MatchedRoute
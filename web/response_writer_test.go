@@ -0,0 +1,116 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContext_Stream(t *testing.T) {
+	testCases := []struct {
+		name     string
+		handler  HandleFunc
+		wantCode int
+		wantResp string
+		// middleware 观察到的状态码和字节数
+		wantStatus  int
+		wantBytes   int
+		wantFlushed bool
+	}{
+		{
+			name: "buffered",
+			handler: func(ctx *Context) {
+				ctx.RespStatusCode = http.StatusCreated
+				ctx.RespData = []byte("hello")
+			},
+			wantCode:   http.StatusCreated,
+			wantResp:   "hello",
+			wantStatus: http.StatusCreated,
+			wantBytes:  5,
+		},
+		{
+			name: "stream",
+			handler: func(ctx *Context) {
+				ctx.RespStatusCode = http.StatusAccepted
+				for i := 0; i < 3; i++ {
+					_, _ = fmt.Fprintf(ctx, "%d;", i)
+					ctx.Flush()
+				}
+			},
+			wantCode:    http.StatusAccepted,
+			wantResp:    "0;1;2;",
+			wantStatus:  http.StatusAccepted,
+			wantBytes:   6,
+			wantFlushed: true,
+		},
+		{
+			// 先设置的 RespData 先写
+			name: "buffered then stream",
+			handler: func(ctx *Context) {
+				ctx.RespData = []byte("a")
+				_, _ = ctx.Write([]byte("b"))
+				ctx.RespData = []byte("c")
+			},
+			wantCode:   http.StatusOK,
+			wantResp:   "abc",
+			wantStatus: http.StatusOK,
+			wantBytes:  3,
+		},
+		{
+			// 直接写 Resp 之后，RespStatusCode 不会导致重复 WriteHeader
+			name: "direct write",
+			handler: func(ctx *Context) {
+				ctx.Resp.WriteHeader(http.StatusCreated)
+				_, _ = ctx.Resp.Write([]byte("direct"))
+				ctx.RespStatusCode = http.StatusInternalServerError
+			},
+			wantCode:   http.StatusCreated,
+			wantResp:   "direct",
+			wantStatus: http.StatusCreated,
+			wantBytes:  6,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var status, bytes int
+			s := NewHTTPServer()
+			s.Use(func(next HandleFunc) HandleFunc {
+				return func(ctx *Context) {
+					next(ctx)
+					status, bytes = ctx.StatusCode(), ctx.BytesWritten()
+				}
+			})
+			s.Get("/", tc.handler)
+			recorder := httptest.NewRecorder()
+			s.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+			assert.Equal(t, tc.wantCode, recorder.Code)
+			assert.Equal(t, tc.wantResp, recorder.Body.String())
+			assert.Equal(t, tc.wantFlushed, recorder.Flushed)
+			assert.Equal(t, tc.wantStatus, status)
+			assert.Equal(t, tc.wantBytes, bytes)
+		})
+	}
+}
+
+func TestHTTPServer_FlushFailed(t *testing.T) {
+	s := NewHTTPServer()
+	s.Get("/", func(ctx *Context) {
+		ctx.RespData = []byte("hello")
+	})
+	// 写失败不会导致进程退出
+	w := &errWriter{ResponseRecorder: httptest.NewRecorder()}
+	s.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+type errWriter struct {
+	*httptest.ResponseRecorder
+}
+
+func (w *errWriter) Write([]byte) (int, error) {
+	return 0, errors.New("mock error")
+}
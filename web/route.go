@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+	"sync"
 )
 
 type router struct {
@@ -17,7 +18,7 @@ const (
 	// 静态路由
 	nodeTypeStatic = iota
 	// 正则路由
-	//nodeTypeReg
+	nodeTypeReg
 	// 路径参数路由
 	nodeTypeParam
 	// 通配符路由
@@ -34,8 +35,13 @@ type node struct {
 	// handler 命中路由之后执行的逻辑
 	handler HandleFunc
 
-	mdls        []Middleware
+	mdls []Middleware
+	// matchedMdls caches the middlewares of the route,
+	// it is resolved once by the first request if mdlsStable,
+	// which means the middlewares do not depend on the values of the segments
 	matchedMdls []Middleware
+	mdlsStable  bool
+	mdlsOnce    sync.Once
 
 	route string
 
@@ -92,7 +98,8 @@ func (r *router) addRoute(method string, path string, handler HandleFunc, ms ...
 			panic("web: route conflict[/]")
 		}
 		root.handler = handler
-		root.mdls = ms
+		root.route = path
+		root.mdls = append(root.mdls, ms...)
 		return
 	}
 
@@ -110,7 +117,27 @@ func (r *router) addRoute(method string, path string, handler HandleFunc, ms ...
 	}
 	root.handler = handler
 	root.route = path
-	root.mdls = ms
+	// keep the middlewares attached by the group
+	root.mdls = append(root.mdls, ms...)
+}
+
+// addMdls attaches ms to the node of path without registering a handler,
+// so that they run for all the routes under path
+func (r *router) addMdls(method string, path string, ms ...Middleware) {
+	root, ok := r.trees[method]
+	if !ok {
+		root = &node{path: "/"}
+		r.trees[method] = root
+	}
+	if path != "/" {
+		for _, s := range strings.Split(path[1:], "/") {
+			if s == "" {
+				panic("web : multiple duplicate '/'")
+			}
+			root = root.childOrCreate(s)
+		}
+	}
+	root.mdls = append(root.mdls, ms...)
 }
 
 // findRoute find the requested route node
@@ -157,26 +184,112 @@ func (r *router) findRoute(method string, path string) (*matchInfo, bool) {
 	}
 
 	segs := strings.Split(strings.Trim(path, "/"), "/")
-	mi := &matchInfo{}
-	cur := root
-	for _, s := range segs {
-		var matchParam bool
-		cur, matchParam, ok = cur.childOf(s)
-		if !ok {
-			return nil, false
+	m := &matcher{segs: segs, stack: make([]*node, 0, len(segs))}
+	var matched []*node
+	switch {
+	case m.match(root, 0):
+		matched = m.stack
+	case m.fallback != nil:
+		// no handler matches, but the caller still knows that the path exists
+		matched = m.fallback
+	default:
+		return nil, false
+	}
+
+	mi := &matchInfo{n: matched[len(matched)-1]}
+	for i, n := range matched {
+		if n.paramName == "" {
+			continue
 		}
-		if matchParam {
-			mi.addValue(root.path[1:], s)
+		val := segs[i]
+		// the '*' at the end swallows all the remaining segments
+		if n.typ == nodeTypeAny && i == len(matched)-1 {
+			val = strings.Join(segs[i:], "/")
 		}
+		mi.addValue(n.paramName, val)
 	}
-	mi.n = cur
-	mi.mdls = r.findMdls(root, segs)
+	mi.mdls = r.matchedMdls(root, matched, segs)
 	return mi, true
 }
 
-func (r *router) findMdls(root *node, segs []string) []Middleware {
+// matcher finds the route by depth-first search,
+// it tries static > regexp > param > '*' children, and backtracks if the deeper segments do not match
+type matcher struct {
+	segs []string
+	// stack is the matched nodes, stack[i] matches segs[i]
+	stack []*node
+	// fallback is the first matched nodes whose last node has no handler
+	fallback []*node
+}
+
+func (m *matcher) match(n *node, i int) bool {
+	if i == len(m.segs) {
+		if n.handler != nil {
+			return true
+		}
+		if m.fallback == nil {
+			m.fallback = append([]*node(nil), m.stack...)
+		}
+		return false
+	}
+	seg := m.segs[i]
+	if n.children != nil {
+		if child, ok := n.children[seg]; ok && m.try(child, i) {
+			return true
+		}
+	}
+	if n.regChild != nil && n.regChild.regExpr.MatchString(seg) && m.try(n.regChild, i) {
+		return true
+	}
+	if n.paramChild != nil && m.try(n.paramChild, i) {
+		return true
+	}
+	if n.starChild != nil {
+		if m.try(n.starChild, i) {
+			return true
+		}
+		// '*' swallows all the remaining segments if nothing deeper matches
+		if n.starChild.handler != nil {
+			m.stack = append(m.stack, n.starChild)
+			return true
+		}
+	}
+	return false
+}
+
+func (m *matcher) try(child *node, i int) bool {
+	m.stack = append(m.stack, child)
+	if m.match(child, i+1) {
+		return true
+	}
+	m.stack = m.stack[:len(m.stack)-1]
+	return false
+}
+
+// matchedMdls returns the middlewares of all the nodes matching segs.
+// They are cached in the matched node unless they depend on the values of the segments,
+// see findMdls
+func (r *router) matchedMdls(root *node, matched []*node, segs []string) []Middleware {
+	n := matched[len(matched)-1]
+	n.mdlsOnce.Do(func() {
+		n.matchedMdls, n.mdlsStable = r.findMdls(root, matched, segs)
+	})
+	if n.mdlsStable {
+		return n.matchedMdls
+	}
+	res, _ := r.findMdls(root, matched, segs)
+	return res
+}
+
+// findMdls collects the middlewares of all the nodes matching segs,
+// and reports whether they are the same for all the paths matching the nodes.
+// The segment matched by a non-static node can be any value,
+// so the static and regexp children which may match it make the result depend on the path if they have middlewares.
+// The segments swallowed by '*' are the same, and their number can vary as well
+func (r *router) findMdls(root *node, matched []*node, segs []string) ([]Middleware, bool) {
 	queue := []*node{root}
 	res := make([]Middleware, 0, 16)
+	stable := true
 	for i := 0; i < len(segs); i++ {
 		seg := segs[i]
 		var children []*node
@@ -184,17 +297,57 @@ func (r *router) findMdls(root *node, segs []string) []Middleware {
 			if len(cur.mdls) > 0 {
 				res = append(res, cur.mdls...)
 			}
+			if stable && i >= len(matched) {
+				stable = !cur.childrenHaveMdls()
+			} else if stable && matched[i].typ != nodeTypeStatic {
+				stable = !cur.variableChildrenHaveMdls(matched[i])
+			}
 			children = append(children, cur.childrenOf(seg)...)
 		}
 		queue = children
 	}
 
+	swallowing := matched[len(matched)-1].typ == nodeTypeAny
 	for _, cur := range queue {
 		if len(cur.mdls) > 0 {
 			res = append(res, cur.mdls...)
 		}
+		// the longer paths may reach the children
+		if stable && swallowing {
+			stable = !cur.childrenHaveMdls()
+		}
 	}
-	return res
+	return res, stable
+}
+
+// variableChildrenHaveMdls reports whether the static and regexp children except matched,
+// which may or may not match a segment, have middlewares
+func (n *node) variableChildrenHaveMdls(matched *node) bool {
+	for _, child := range n.children {
+		if child != matched && child.hasMdls() {
+			return true
+		}
+	}
+	return n.regChild != nil && n.regChild != matched && n.regChild.hasMdls()
+}
+
+func (n *node) childrenHaveMdls() bool {
+	for _, child := range n.children {
+		if child.hasMdls() {
+			return true
+		}
+	}
+	for _, child := range []*node{n.regChild, n.paramChild, n.starChild} {
+		if child != nil && child.hasMdls() {
+			return true
+		}
+	}
+	return false
+}
+
+// hasMdls reports whether n or any of its descendants has middlewares
+func (n *node) hasMdls() bool {
+	return len(n.mdls) > 0 || n.childrenHaveMdls()
 }
 
 func (n *node) childrenOf(path string) []*node {
@@ -209,32 +362,15 @@ func (n *node) childrenOf(path string) []*node {
 	if n.paramChild != nil {
 		res = append(res, n.paramChild)
 	}
-	//if n.regChild != nil {
-	//	res = append(res, n.regChild)
-	//}
+	if n.regChild != nil && n.regChild.regExpr.MatchString(path) {
+		res = append(res, n.regChild)
+	}
 	if static != nil {
 		res = append(res, static)
 	}
 	return res
 }
 
-func (n *node) childOf(path string) (*node, bool, bool) {
-	if n.children == nil {
-		if n.paramChild != nil {
-			return n.paramChild, true, true
-		}
-		return n.starChild, false, n.starChild != nil
-	}
-	res, ok := n.children[path]
-	if !ok {
-		if n.paramChild != nil {
-			return n.paramChild, true, true
-		}
-		return n.starChild, false, n.starChild != nil
-	}
-	return res, false, ok
-}
-
 func (n *node) childOfNonStatic(path string) (*node, bool) {
 	if n.regChild != nil {
 		if n.regChild.regExpr.Match([]byte(path)) {
@@ -247,23 +383,26 @@ func (n *node) childOfNonStatic(path string) (*node, bool) {
 	return n.starChild, n.starChild != nil
 }
 func (n *node) childOrCreate(path string) *node {
-	if path == "*" {
+	// '*' or the named '*name' whose matched segments are recorded in path params
+	if path[0] == '*' {
 		if n.paramChild != nil {
 			panic(fmt.Sprintf("web: illegal route : already has param route here [%s]", path))
 		}
 		if n.regChild != nil {
-			panic(fmt.Sprintf("web: illegal route : already has param route here [%s]", path))
+			panic(fmt.Sprintf("web: illegal route : already has regexp route here [%s]", path))
 		}
 		if n.starChild == nil {
-			n.starChild = &node{path: path, typ: nodeTypeAny}
+			n.starChild = &node{path: path, paramName: path[1:], typ: nodeTypeAny}
+		} else if n.starChild.path != path {
+			panic(fmt.Sprintf("web: illegal route : already has '*' route here %s", path))
 		}
 		return n.starChild
 	}
 	if path[0] == ':' {
-		paramName, _, _ := n.parseParam(path)
-		//if isReg {
-		//	return n.childOrCreateReg(path, expr, paramName)
-		//}
+		paramName, expr, isReg := n.parseParam(path)
+		if isReg {
+			return n.childOrCreateReg(path, expr, paramName)
+		}
 		return n.childOrCreateParam(path, paramName)
 
 	}
@@ -297,28 +436,28 @@ func (n *node) childOrCreateParam(path string, paramName string) *node {
 
 }
 
-//func (n *node) childOrCreateReg(path string, expr string, paramName string) *node {
-//
-//	if n.starChild != nil {
-//		panic(fmt.Sprintf("web: illegal route : already has '*' route here %s", path))
-//	}
-//	if n.paramChild != nil {
-//		panic(fmt.Sprintf("web: illegal route : already has param route here %s", path))
-//	}
-//	if n.regChild != nil {
-//		if n.regChild.regExpr.String() != expr || n.paramName != paramName {
-//			panic(fmt.Sprintf("web: illegal route : already has regexp route here %s", path))
-//		}
-//	} else {
-//		regExpr, err := regexp.Compile(expr)
-//		if err != nil {
-//			panic(fmt.Errorf("web: illegal route : invalid regexp %w", err))
-//		}
-//		n.regChild = &node{path: path, regExpr: regExpr, paramName: paramName, typ: nodeTypeReg}
-//	}
-//	return n.regChild
-//
-//}
+func (n *node) childOrCreateReg(path string, expr string, paramName string) *node {
+	if n.starChild != nil {
+		panic(fmt.Sprintf("web: illegal route : already has '*' route here %s", path))
+	}
+	if n.paramChild != nil {
+		panic(fmt.Sprintf("web: illegal route : already has param route here %s", path))
+	}
+	// the whole segment must match the expression
+	expr = "^(?:" + expr + ")$"
+	if n.regChild != nil {
+		if n.regChild.regExpr.String() != expr || n.regChild.paramName != paramName {
+			panic(fmt.Sprintf("web: illegal route : already has regexp route here %s", path))
+		}
+	} else {
+		regExpr, err := regexp.Compile(expr)
+		if err != nil {
+			panic(fmt.Errorf("web: illegal route : invalid regexp %w", err))
+		}
+		n.regChild = &node{path: path, regExpr: regExpr, paramName: paramName, typ: nodeTypeReg}
+	}
+	return n.regChild
+}
 
 func (m *matchInfo) addValue(key string, value string) {
 	if m.pathParams == nil {
@@ -0,0 +1,106 @@
+package web
+
+import (
+	"net/http"
+)
+
+// RouterGroup registers routes under the same prefix,
+// and its middlewares run for all the routes under the prefix
+type RouterGroup struct {
+	prefix string
+	mdls   []Middleware
+	parent *RouterGroup
+	s      *HTTPServer
+	// attached records the methods whose tree already has mdls on the prefix node
+	attached map[string]bool
+}
+
+// Group creates a RouterGroup, prefix must start with '/' and cannot end with '/'
+func (s *HTTPServer) Group(prefix string, mdls ...Middleware) *RouterGroup {
+	return newRouterGroup(s, nil, prefix, mdls)
+}
+
+// Group creates a nested RouterGroup whose prefix is relative to g
+func (g *RouterGroup) Group(prefix string, mdls ...Middleware) *RouterGroup {
+	return newRouterGroup(g.s, g, prefix, mdls)
+}
+
+func newRouterGroup(s *HTTPServer, parent *RouterGroup, prefix string, mdls []Middleware) *RouterGroup {
+	if prefix == "" || prefix[0] != '/' {
+		panic("web: group prefix must starts with '/'")
+	}
+	if prefix != "/" && prefix[len(prefix)-1] == '/' {
+		panic("web: group prefix cannot end with '/'")
+	}
+	if prefix == "/" {
+		prefix = ""
+	}
+	if parent != nil {
+		prefix = parent.prefix + prefix
+	}
+	return &RouterGroup{
+		prefix:   prefix,
+		mdls:     mdls,
+		parent:   parent,
+		s:        s,
+		attached: map[string]bool{},
+	}
+}
+
+func (g *RouterGroup) Get(path string, handler HandleFunc) {
+	g.handle(http.MethodGet, path, handler)
+}
+
+func (g *RouterGroup) Post(path string, handler HandleFunc) {
+	g.handle(http.MethodPost, path, handler)
+}
+
+func (g *RouterGroup) Put(path string, handler HandleFunc) {
+	g.handle(http.MethodPut, path, handler)
+}
+
+func (g *RouterGroup) Delete(path string, handler HandleFunc) {
+	g.handle(http.MethodDelete, path, handler)
+}
+
+func (g *RouterGroup) Patch(path string, handler HandleFunc) {
+	g.handle(http.MethodPatch, path, handler)
+}
+
+// Any registers handler for all the HTTP methods
+func (g *RouterGroup) Any(path string, handler HandleFunc) {
+	for _, method := range anyMethods {
+		g.handle(method, path, handler)
+	}
+}
+
+// handle registers the route with the full path,
+// path "/" means the prefix itself
+func (g *RouterGroup) handle(method string, path string, handler HandleFunc) {
+	g.attach(method)
+	full := g.prefix + path
+	if g.prefix != "" && path == "/" {
+		full = g.prefix
+	}
+	g.s.addRoute(method, full, handler)
+}
+
+// attach puts the mdls of g and its parents on the prefix nodes of the method tree,
+// the parents go first so that their mdls are found first by findMdls
+func (g *RouterGroup) attach(method string) {
+	if g.parent != nil {
+		g.parent.attach(method)
+	}
+	if g.attached[method] {
+		return
+	}
+	g.attached[method] = true
+	if len(g.mdls) == 0 {
+		return
+	}
+	prefix := g.prefix
+	if prefix == "" {
+		prefix = "/"
+	}
+	g.s.addMdls(method, prefix, g.mdls...)
+}
@@ -0,0 +1,116 @@
+package web
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRouterGroup(t *testing.T) {
+	var mdlBuilder = func(i byte) Middleware {
+		return func(next HandleFunc) HandleFunc {
+			return func(ctx *Context) {
+				ctx.RespData = append(ctx.RespData, i)
+				next(ctx)
+			}
+		}
+	}
+	mockHandler := func(ctx *Context) {}
+
+	s := NewHTTPServer()
+	api := s.Group("/api", mdlBuilder('a'))
+	api.Get("/", mockHandler)
+	v1 := api.Group("/v1", mdlBuilder('1'))
+	v1.Get("/user", mockHandler)
+	v1.Post("/user", mockHandler)
+	v1.Any("/order", mockHandler)
+	// 没有 middleware 的分组
+	s.Group("/admin").Delete("/user", mockHandler)
+	s.Get("/home", mockHandler)
+
+	testCases := []struct {
+		name     string
+		method   string
+		path     string
+		found    bool
+		wantResp string
+	}{
+		{
+			name:     "group root",
+			method:   http.MethodGet,
+			path:     "/api",
+			found:    true,
+			wantResp: "a",
+		},
+		{
+			name:     "nested",
+			method:   http.MethodGet,
+			path:     "/api/v1/user",
+			found:    true,
+			wantResp: "a1",
+		},
+		{
+			name:     "nested post",
+			method:   http.MethodPost,
+			path:     "/api/v1/user",
+			found:    true,
+			wantResp: "a1",
+		},
+		{
+			name:     "any",
+			method:   http.MethodPatch,
+			path:     "/api/v1/order",
+			found:    true,
+			wantResp: "a1",
+		},
+		{
+			name:   "method not registered",
+			method: http.MethodPut,
+			path:   "/api/v1/user",
+		},
+		{
+			name:   "no middleware",
+			method: http.MethodDelete,
+			path:   "/admin/user",
+			found:  true,
+		},
+		{
+			name:   "out of group",
+			method: http.MethodGet,
+			path:   "/home",
+			found:  true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mi, found := s.findRoute(tc.method, tc.path)
+			assert.Equal(t, tc.found, found)
+			if !found {
+				return
+			}
+			assert.NotNil(t, mi.n.handler)
+			var root HandleFunc = func(ctx *Context) {
+				assert.Equal(t, tc.wantResp, string(ctx.RespData))
+			}
+			for i := len(mi.mdls) - 1; i >= 0; i-- {
+				root = mi.mdls[i](root)
+			}
+			root(&Context{})
+		})
+	}
+}
+
+func TestRouterGroup_Panic(t *testing.T) {
+	s := NewHTTPServer()
+	assert.PanicsWithValue(t, "web: group prefix must starts with '/'", func() {
+		s.Group("api")
+	})
+	assert.PanicsWithValue(t, "web: group prefix cannot end with '/'", func() {
+		s.Group("/api/")
+	})
+	assert.PanicsWithValue(t, "web: path cannot end with '/'", func() {
+		s.Group("/api").Get("/user/", func(ctx *Context) {})
+	})
+}
@@ -76,6 +76,10 @@ func Test_router_AddRoute(t *testing.T) {
 			method: http.MethodDelete,
 			path:   "/reg/:id(.*)",
 		},
+		{
+			method: http.MethodDelete,
+			path:   "/:name(^.+$)/abc",
+		},
 	}
 
 	mockHandler := func(ctx *Context) {}
@@ -148,32 +152,32 @@ func Test_router_AddRoute(t *testing.T) {
 				},
 				typ: nodeTypeStatic,
 			},
-			//http.MethodDelete: {
-			//	path: "/",
-			//	children: map[string]*node{
-			//		"reg": {
-			//			path: "reg",
-			//			typ:  nodeTypeStatic,
-			//			regChild: &node{
-			//				path:      ":id(.*)",
-			//				paramName: "id",
-			//				typ:       nodeTypeReg,
-			//				handler:   mockHandler,
-			//			},
-			//		},
-			//	},
-			//	regChild: &node{
-			//		path:      ":name(^.+$)",
-			//		paramName: "name",
-			//		typ:       nodeTypeReg,
-			//		children: map[string]*node{
-			//			"abc": {
-			//				path:    "abc",
-			//				handler: mockHandler,
-			//			},
-			//		},
-			//	},
-			//},
+			http.MethodDelete: {
+				path: "/",
+				children: map[string]*node{
+					"reg": {
+						path: "reg",
+						typ:  nodeTypeStatic,
+						regChild: &node{
+							path:      ":id(.*)",
+							paramName: "id",
+							typ:       nodeTypeReg,
+							handler:   mockHandler,
+						},
+					},
+				},
+				regChild: &node{
+					path:      ":name(^.+$)",
+					paramName: "name",
+					typ:       nodeTypeReg,
+					children: map[string]*node{
+						"abc": {
+							path:    "abc",
+							handler: mockHandler,
+						},
+					},
+				},
+			},
 		},
 	}
 	msg, ok := wantRouter.equal(r)
@@ -234,20 +238,20 @@ func Test_router_AddRoute(t *testing.T) {
 		r.addRoute(http.MethodGet, "/*", mockHandler)
 	})
 	r = newRouter()
-	//assert.PanicsWithValue(t, "web: illegal route : already has param route here :id(.*)", func() {
-	//	r.addRoute(http.MethodGet, "/a/b/:id", mockHandler)
-	//	r.addRoute(http.MethodGet, "/a/b/:id(.*)", mockHandler)
-	//})
-	//r = newRouter()
-	//assert.PanicsWithValue(t, "web: illegal route : already has regexp route here [*]", func() {
-	//	r.addRoute(http.MethodGet, "/a/b/:id(.*)", mockHandler)
-	//	r.addRoute(http.MethodGet, "/a/b/*", mockHandler)
-	//})
-	//r = newRouter()
-	//assert.PanicsWithValue(t, "web: illegal route : already has regexp route here :id", func() {
-	//	r.addRoute(http.MethodGet, "/a/b/:id(.*)", mockHandler)
-	//	r.addRoute(http.MethodGet, "/a/b/:id", mockHandler)
-	//})
+	assert.PanicsWithValue(t, "web: illegal route : already has param route here :id(.*)", func() {
+		r.addRoute(http.MethodGet, "/a/b/:id", mockHandler)
+		r.addRoute(http.MethodGet, "/a/b/:id(.*)", mockHandler)
+	})
+	r = newRouter()
+	assert.PanicsWithValue(t, "web: illegal route : already has regexp route here [*]", func() {
+		r.addRoute(http.MethodGet, "/a/b/:id(.*)", mockHandler)
+		r.addRoute(http.MethodGet, "/a/b/*", mockHandler)
+	})
+	r = newRouter()
+	assert.PanicsWithValue(t, "web: illegal route : already has regexp route here :id", func() {
+		r.addRoute(http.MethodGet, "/a/b/:id(.*)", mockHandler)
+		r.addRoute(http.MethodGet, "/a/b/:id", mockHandler)
+	})
 	// 参数冲突
 	assert.PanicsWithValue(t, "web: illegal route : already has param route here :id", func() {
 		r.addRoute(http.MethodGet, "/a/b/c/:id", mockHandler)
@@ -845,6 +849,148 @@ func Test_router_findRoute(t *testing.T) {
 
 }
 
+func Test_router_findRoute_Reg(t *testing.T) {
+	static := func(ctx *Context) {}
+	reg := func(ctx *Context) {}
+	r := newRouter()
+	r.addRoute(http.MethodGet, "/user/me", static)
+	r.addRoute(http.MethodGet, "/user/:id([0-9]+)", reg)
+	r.addRoute(http.MethodGet, "/user/:id([0-9]+)/home", reg)
+
+	testCases := []struct {
+		name        string
+		path        string
+		found       bool
+		wantHandler HandleFunc
+		wantParams  map[string]string
+	}{
+		{
+			// 静态路由优先于正则路由
+			name:        "static first",
+			path:        "/user/me",
+			found:       true,
+			wantHandler: static,
+		},
+		{
+			name:        "reg",
+			path:        "/user/123",
+			found:       true,
+			wantHandler: reg,
+			wantParams:  map[string]string{"id": "123"},
+		},
+		{
+			name:        "reg in middle",
+			path:        "/user/123/home",
+			found:       true,
+			wantHandler: reg,
+			wantParams:  map[string]string{"id": "123"},
+		},
+		{
+			// 整段都要匹配
+			name: "partial match",
+			path: "/user/12a",
+		},
+		{
+			name: "not match",
+			path: "/user/abc",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mi, found := r.findRoute(http.MethodGet, tc.path)
+			assert.Equal(t, tc.found, found)
+			if !found {
+				return
+			}
+			assert.Equal(t, reflect.ValueOf(tc.wantHandler), reflect.ValueOf(mi.n.handler))
+			assert.Equal(t, tc.wantParams, mi.pathParams)
+		})
+	}
+
+	assert.PanicsWithValue(t, "web: illegal route : already has regexp route here :id([a-z]+)", func() {
+		r.addRoute(http.MethodGet, "/user/:id([a-z]+)", reg)
+	})
+}
+
+func Test_router_findRoute_Backtrack(t *testing.T) {
+	mockHandler := func(ctx *Context) {}
+	r := newRouter()
+	r.addRoute(http.MethodGet, "/a/b/c", mockHandler)
+	r.addRoute(http.MethodGet, "/a/:id/d", mockHandler)
+	r.addRoute(http.MethodGet, "/a/:id/*", mockHandler)
+	r.addRoute(http.MethodGet, "/static/*filepath", mockHandler)
+	r.addRoute(http.MethodGet, "/user/admin/settings", mockHandler)
+	r.addRoute(http.MethodGet, "/user/:name([a-z]+)/profile", mockHandler)
+
+	testCases := []struct {
+		name       string
+		path       string
+		found      bool
+		wantRoute  string
+		wantParams map[string]string
+	}{
+		{
+			name:      "static",
+			path:      "/a/b/c",
+			found:     true,
+			wantRoute: "/a/b/c",
+		},
+		{
+			// /a/b 下面没有 d，回溯到 /a/:id/d
+			name:       "backtrack to param",
+			path:       "/a/b/d",
+			found:      true,
+			wantRoute:  "/a/:id/d",
+			wantParams: map[string]string{"id": "b"},
+		},
+		{
+			// 回溯到 /a/:id/*，通配符吞掉剩下的所有段
+			name:       "backtrack to star",
+			path:       "/a/b/e/f",
+			found:      true,
+			wantRoute:  "/a/:id/*",
+			wantParams: map[string]string{"id": "b"},
+		},
+		{
+			name:       "named star",
+			path:       "/static/js/app.js",
+			found:      true,
+			wantRoute:  "/static/*filepath",
+			wantParams: map[string]string{"filepath": "js/app.js"},
+		},
+		{
+			// 静态路由后续不匹配，回溯到正则路由
+			name:       "backtrack to reg",
+			path:       "/user/admin/profile",
+			found:      true,
+			wantRoute:  "/user/:name([a-z]+)/profile",
+			wantParams: map[string]string{"name": "admin"},
+		},
+		{
+			name:       "reg",
+			path:       "/user/tom/profile",
+			found:      true,
+			wantRoute:  "/user/:name([a-z]+)/profile",
+			wantParams: map[string]string{"name": "tom"},
+		},
+		{
+			name: "not found",
+			path: "/user/tom/detail",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mi, found := r.findRoute(http.MethodGet, tc.path)
+			assert.Equal(t, tc.found, found)
+			if !found {
+				return
+			}
+			assert.Equal(t, tc.wantRoute, mi.n.route)
+			assert.Equal(t, tc.wantParams, mi.pathParams)
+		})
+	}
+}
+
 func Test_findRoute_Middleware(t *testing.T) {
 	var mdlBuilder = func(i byte) Middleware {
 		return func(next HandleFunc) HandleFunc {
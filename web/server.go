@@ -1,8 +1,15 @@
 package web
 
 import (
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
 	"log"
+	"net"
 	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 )
 
 type HandleFunc func(ctx *Context)
@@ -19,26 +26,126 @@ type HTTPServer struct {
 	router
 	mdls      []Middleware
 	tplEngine TemplateEngine
+
+	// notFound and methodNotAllowed run inside the global middlewares like the normal handlers
+	notFound         HandleFunc
+	methodNotAllowed HandleFunc
+
+	// srv serves s, see Start and Shutdown
+	srv *http.Server
+	// listener is used by Start and StartTLS instead of listening on addr
+	listener net.Listener
+	h2c      bool
+	// certReload is the interval of checking the certificate files of StartTLS
+	certReload   time.Duration
+	onStart      []Hook
+	onShutdown   []Hook
+	hookTimeout  time.Duration
+	drainPeriod  time.Duration
+	shutdownOnce sync.Once
+	shutdownErr  error
 }
 
 type HTTPServerOption func(server *HTTPServer)
 
 func NewHTTPServer(opts ...HTTPServerOption) *HTTPServer {
 	s := &HTTPServer{
-		router: newRouter(),
+		router:           newRouter(),
+		notFound:         defaultNotFound,
+		methodNotAllowed: defaultMethodNotAllowed,
+		hookTimeout:      defaultHookTimeout,
 	}
+	s.srv = &http.Server{Handler: s}
 	for _, opt := range opts {
 		opt(s)
 	}
+	if s.h2c {
+		s.srv.Handler = h2c.NewHandler(s, &http2.Server{IdleTimeout: s.srv.IdleTimeout})
+	}
 	return s
 }
 
+// ServerWithReadTimeout sets the maximum duration for reading the entire request, including the body
+func ServerWithReadTimeout(d time.Duration) HTTPServerOption {
+	return func(server *HTTPServer) {
+		server.srv.ReadTimeout = d
+	}
+}
+
+// ServerWithWriteTimeout sets the maximum duration before timing out writes of the response
+func ServerWithWriteTimeout(d time.Duration) HTTPServerOption {
+	return func(server *HTTPServer) {
+		server.srv.WriteTimeout = d
+	}
+}
+
+// ServerWithIdleTimeout sets the maximum amount of time to wait for the next request when keep-alives are enabled
+func ServerWithIdleTimeout(d time.Duration) HTTPServerOption {
+	return func(server *HTTPServer) {
+		server.srv.IdleTimeout = d
+	}
+}
+
+// ServerWithMaxHeaderBytes sets the maximum number of bytes of the request header
+func ServerWithMaxHeaderBytes(n int) HTTPServerOption {
+	return func(server *HTTPServer) {
+		server.srv.MaxHeaderBytes = n
+	}
+}
+
+// ServerWithListener makes Start and StartTLS serve on l instead of listening on addr
+func ServerWithListener(l net.Listener) HTTPServerOption {
+	return func(server *HTTPServer) {
+		server.listener = l
+	}
+}
+
+// ServerWithH2C enables HTTP/2 without TLS, aka h2c
+func ServerWithH2C() HTTPServerOption {
+	return func(server *HTTPServer) {
+		server.h2c = true
+	}
+}
+
 func ServerWithTemplateEngine(engine TemplateEngine) HTTPServerOption {
 	return func(server *HTTPServer) {
 		server.tplEngine = engine
 	}
 }
 
+// ServerWithNotFoundHandler replaces the default 404 response,
+// RespStatusCode is http.StatusNotFound unless handler changes it
+func ServerWithNotFoundHandler(handler HandleFunc) HTTPServerOption {
+	return func(server *HTTPServer) {
+		server.notFound = func(ctx *Context) {
+			ctx.RespStatusCode = http.StatusNotFound
+			handler(ctx)
+		}
+	}
+}
+
+// ServerWithMethodNotAllowedHandler replaces the default 405 response,
+// the Allow header is already set when handler runs,
+// and RespStatusCode is http.StatusMethodNotAllowed unless handler changes it
+func ServerWithMethodNotAllowedHandler(handler HandleFunc) HTTPServerOption {
+	return func(server *HTTPServer) {
+		server.methodNotAllowed = func(ctx *Context) {
+			ctx.RespStatusCode = http.StatusMethodNotAllowed
+			handler(ctx)
+		}
+	}
+}
+
+func defaultNotFound(ctx *Context) {
+	ctx.RespStatusCode = http.StatusNotFound
+	ctx.RespData = []byte("404 page not found")
+}
+
+func defaultMethodNotAllowed(ctx *Context) {
+	ctx.RespStatusCode = http.StatusMethodNotAllowed
+	ctx.RespData = []byte("405 method not allowed")
+}
+
 func (s *HTTPServer) Use(mdls ...Middleware) {
 	if s.mdls == nil {
 		s.mdls = mdls
@@ -54,7 +161,7 @@ func (s *HTTPServer) UseV1(method string, path string, mdls ...Middleware) {
 func (s *HTTPServer) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
 	ctx := &Context{
 		Req:       request,
-		Resp:      writer,
+		Resp:      newResponseWriter(writer),
 		tplEngine: s.tplEngine,
 	}
 	// the last one should be HTTPServer to execute route matching and user code
@@ -75,35 +182,132 @@ func (s *HTTPServer) ServeHTTP(writer http.ResponseWriter, request *http.Request
 	root(ctx)
 }
 
+// flushResp writes RespStatusCode and RespData,
+// RespStatusCode is ignored if the header has been written by streaming
 func (s *HTTPServer) flushResp(ctx *Context) {
-	if ctx.RespStatusCode > 0 {
-		ctx.Resp.WriteHeader(ctx.RespStatusCode)
-	}
-	_, err := ctx.Resp.Write(ctx.RespData)
-	if err != nil {
-		log.Fatalln("Web: Flush Failed", err)
+	if err := ctx.writeBuffered(); err != nil {
+		log.Println("web: failed to flush response", err)
 	}
 }
 
-func (s *HTTPServer) Start(addr string) error {
-	return http.ListenAndServe(addr, s)
+// anyMethods are the methods registered by Any, in the order listed in the Allow header
+var anyMethods = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+	http.MethodConnect,
+	http.MethodOptions,
+	http.MethodTrace,
 }
 
 func (s *HTTPServer) Post(path string, handler HandleFunc) {
 	s.addRoute(http.MethodPost, path, handler)
 }
 
+// Get registers handler for GET, and HEAD is answered by it unless Head is registered
 func (s *HTTPServer) Get(path string, handler HandleFunc) {
 	s.addRoute(http.MethodGet, path, handler)
 }
 
+func (s *HTTPServer) Put(path string, handler HandleFunc) {
+	s.addRoute(http.MethodPut, path, handler)
+}
+
+func (s *HTTPServer) Delete(path string, handler HandleFunc) {
+	s.addRoute(http.MethodDelete, path, handler)
+}
+
+func (s *HTTPServer) Patch(path string, handler HandleFunc) {
+	s.addRoute(http.MethodPatch, path, handler)
+}
+
+func (s *HTTPServer) Head(path string, handler HandleFunc) {
+	s.addRoute(http.MethodHead, path, handler)
+}
+
+// Options registers handler for OPTIONS,
+// otherwise OPTIONS is answered with the Allow header
+func (s *HTTPServer) Options(path string, handler HandleFunc) {
+	s.addRoute(http.MethodOptions, path, handler)
+}
+
+func (s *HTTPServer) Connect(path string, handler HandleFunc) {
+	s.addRoute(http.MethodConnect, path, handler)
+}
+
+func (s *HTTPServer) Trace(path string, handler HandleFunc) {
+	s.addRoute(http.MethodTrace, path, handler)
+}
+
+// Any registers handler for all the HTTP methods
+func (s *HTTPServer) Any(path string, handler HandleFunc) {
+	for _, method := range anyMethods {
+		s.addRoute(method, path, handler)
+	}
+}
+
 func (s *HTTPServer) serve(ctx *Context) {
-	mi, ok := s.findRoute(ctx.Req.Method, ctx.Req.URL.Path)
+	method, path := ctx.Req.Method, ctx.Req.URL.Path
+	mi, ok := s.findRoute(method, path)
+	if (!ok || mi.n.handler == nil) && method == http.MethodHead {
+		// net/http discards the body of HEAD
+		mi, ok = s.findRoute(http.MethodGet, path)
+	}
 	if !ok || mi.n == nil || mi.n.handler == nil {
-		ctx.Resp.WriteHeader(404)
-		ctx.Resp.Write([]byte("404 page not found"))
+		allowed := s.allowedMethods(path)
+		if len(allowed) == 0 {
+			s.notFound(ctx)
+			return
+		}
+		ctx.Resp.Header().Set("Allow", strings.Join(allowed, ", "))
+		if method == http.MethodOptions {
+			ctx.RespStatusCode = http.StatusNoContent
+			return
+		}
+		s.methodNotAllowed(ctx)
 		return
 	}
 	ctx.PathParams = mi.pathParams
-	mi.n.handler(ctx)
+	ctx.MatchedRoute = mi.n.route
+	// the route middlewares run after the global ones
+	root := mi.n.handler
+	for i := len(mi.mdls) - 1; i >= 0; i-- {
+		root = mi.mdls[i](root)
+	}
+	root(ctx)
+}
+
+// allowedMethods returns the methods which have handler for path,
+// HEAD and OPTIONS are included since they are answered automatically
+func (s *HTTPServer) allowedMethods(path string) []string {
+	registered := make(map[string]bool, len(s.trees))
+	for method := range s.trees {
+		if mi, ok := s.findRoute(method, path); ok && mi.n.handler != nil {
+			registered[method] = true
+		}
+	}
+	if len(registered) == 0 {
+		return nil
+	}
+	if registered[http.MethodGet] {
+		registered[http.MethodHead] = true
+	}
+	registered[http.MethodOptions] = true
+	res := make([]string, 0, len(registered))
+	for _, method := range anyMethods {
+		if registered[method] {
+			res = append(res, method)
+			delete(registered, method)
+		}
+	}
+	// the custom methods
+	others := make([]string, 0, len(registered))
+	for method := range registered {
+		others = append(others, method)
+	}
+	sort.Strings(others)
+	return append(res, others...)
 }
@@ -4,7 +4,12 @@ import (
 	"bytes"
 	"fmt"
 	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
 )
 
 func TestServer(t *testing.T) {
@@ -68,3 +73,292 @@ func TestServerWithRenderEngine(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestHTTPServer_RouteMiddleware(t *testing.T) {
+	var mdlBuilder = func(i byte) Middleware {
+		return func(next HandleFunc) HandleFunc {
+			return func(ctx *Context) {
+				ctx.RespData = append(ctx.RespData, i)
+				next(ctx)
+			}
+		}
+	}
+	s := NewHTTPServer()
+	s.Use(mdlBuilder('g'))
+	s.UseV1(http.MethodGet, "/a/*", mdlBuilder('*'))
+	s.UseV1(http.MethodGet, "/a/b", mdlBuilder('b'))
+	s.Group("/a", mdlBuilder('a')).Get("/b", func(ctx *Context) {
+		ctx.RespData = append(ctx.RespData, 'h')
+	})
+	s.Get("/a/*", func(ctx *Context) {
+		ctx.RespData = append(ctx.RespData, 'h')
+	})
+
+	testCases := []struct {
+		name     string
+		path     string
+		wantCode int
+		wantResp string
+	}{
+		{
+			name:     "static",
+			path:     "/a/b",
+			wantCode: http.StatusOK,
+			wantResp: "ga*bh",
+		},
+		{
+			// 第二次命中缓存
+			name:     "static cached",
+			path:     "/a/b",
+			wantCode: http.StatusOK,
+			wantResp: "ga*bh",
+		},
+		{
+			name:     "star",
+			path:     "/a/c",
+			wantCode: http.StatusOK,
+			wantResp: "ga*h",
+		},
+		{
+			name:     "not found",
+			path:     "/b",
+			wantCode: http.StatusNotFound,
+			wantResp: "404 page not found",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			recorder := httptest.NewRecorder()
+			s.ServeHTTP(recorder, req)
+			assert.Equal(t, tc.wantCode, recorder.Code)
+			assert.Equal(t, tc.wantResp, recorder.Body.String())
+		})
+	}
+}
+
+func TestHTTPServer_Methods(t *testing.T) {
+	s := NewHTTPServer()
+	s.Get("/user", func(ctx *Context) {
+		ctx.Resp.Header().Set("X-Handler", "get")
+		ctx.RespData = []byte("user")
+	})
+	s.Put("/user", func(ctx *Context) {})
+	s.Delete("/user", func(ctx *Context) {})
+	s.Head("/order", func(ctx *Context) {
+		ctx.Resp.Header().Set("X-Handler", "head")
+	})
+	s.Get("/order", func(ctx *Context) {
+		ctx.Resp.Header().Set("X-Handler", "get")
+	})
+	s.Options("/order", func(ctx *Context) {
+		ctx.RespStatusCode = http.StatusOK
+	})
+	s.Any("/any", func(ctx *Context) {})
+
+	testCases := []struct {
+		name        string
+		method      string
+		path        string
+		wantCode    int
+		wantHandler string
+		wantAllow   string
+	}{
+		{
+			name:        "get",
+			method:      http.MethodGet,
+			path:        "/user",
+			wantCode:    http.StatusOK,
+			wantHandler: "get",
+		},
+		{
+			// 没有注册 HEAD，使用 GET
+			name:        "head from get",
+			method:      http.MethodHead,
+			path:        "/user",
+			wantCode:    http.StatusOK,
+			wantHandler: "get",
+		},
+		{
+			name:        "head registered",
+			method:      http.MethodHead,
+			path:        "/order",
+			wantCode:    http.StatusOK,
+			wantHandler: "head",
+		},
+		{
+			name:      "options",
+			method:    http.MethodOptions,
+			path:      "/user",
+			wantCode:  http.StatusNoContent,
+			wantAllow: "GET, HEAD, PUT, DELETE, OPTIONS",
+		},
+		{
+			name:     "options registered",
+			method:   http.MethodOptions,
+			path:     "/order",
+			wantCode: http.StatusOK,
+		},
+		{
+			name:      "method not allowed",
+			method:    http.MethodPost,
+			path:      "/user",
+			wantCode:  http.StatusMethodNotAllowed,
+			wantAllow: "GET, HEAD, PUT, DELETE, OPTIONS",
+		},
+		{
+			name:     "any",
+			method:   http.MethodTrace,
+			path:     "/any",
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "not found",
+			method:   http.MethodPost,
+			path:     "/abc",
+			wantCode: http.StatusNotFound,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.path, nil)
+			recorder := httptest.NewRecorder()
+			s.ServeHTTP(recorder, req)
+			assert.Equal(t, tc.wantCode, recorder.Code)
+			assert.Equal(t, tc.wantHandler, recorder.Header().Get("X-Handler"))
+			assert.Equal(t, tc.wantAllow, recorder.Header().Get("Allow"))
+		})
+	}
+}
+
+func TestHTTPServer_ErrorHandler(t *testing.T) {
+	s := NewHTTPServer(
+		ServerWithNotFoundHandler(func(ctx *Context) {
+			_ = ctx.RespJSON(http.StatusNotFound, map[string]string{"error": "not found"})
+		}),
+		ServerWithMethodNotAllowedHandler(func(ctx *Context) {
+			ctx.RespData = []byte(`{"error":"method not allowed"}`)
+		}),
+	)
+	// 全局 middleware 也能看到错误响应
+	s.Use(func(next HandleFunc) HandleFunc {
+		return func(ctx *Context) {
+			next(ctx)
+			ctx.Resp.Header().Set("X-Status", strconv.Itoa(ctx.RespStatusCode))
+		}
+	})
+	s.Get("/user", func(ctx *Context) {})
+
+	testCases := []struct {
+		name      string
+		method    string
+		path      string
+		wantCode  int
+		wantResp  string
+		wantAllow string
+	}{
+		{
+			name:     "not found",
+			method:   http.MethodGet,
+			path:     "/order",
+			wantCode: http.StatusNotFound,
+			wantResp: `{"error":"not found"}`,
+		},
+		{
+			name:      "method not allowed",
+			method:    http.MethodPost,
+			path:      "/user",
+			wantCode:  http.StatusMethodNotAllowed,
+			wantResp:  `{"error":"method not allowed"}`,
+			wantAllow: "GET, HEAD, OPTIONS",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.path, nil)
+			recorder := httptest.NewRecorder()
+			s.ServeHTTP(recorder, req)
+			assert.Equal(t, tc.wantCode, recorder.Code)
+			assert.Equal(t, strconv.Itoa(tc.wantCode), recorder.Header().Get("X-Status"))
+			assert.Equal(t, tc.wantResp, recorder.Body.String())
+			assert.Equal(t, tc.wantAllow, recorder.Header().Get("Allow"))
+		})
+	}
+}
+
+func TestHTTPServer_RouteMiddlewareCache(t *testing.T) {
+	var mdlBuilder = func(i byte) Middleware {
+		return func(next HandleFunc) HandleFunc {
+			return func(ctx *Context) {
+				ctx.RespData = append(ctx.RespData, i)
+				next(ctx)
+			}
+		}
+	}
+	handler := func(ctx *Context) {
+		ctx.RespData = append(ctx.RespData, 'h')
+	}
+	s := NewHTTPServer()
+	user := s.Group("/user", mdlBuilder('u'))
+	user.Get("/:id", handler)
+	user.Get("/:id/order/:oid(^[0-9]+$)", handler)
+	s.Get("/file/*path", handler)
+	// vip 有自己的 middleware，所以 /order/:id 的 middleware 取决于路径
+	s.Get("/order/:id", handler)
+	s.Group("/order/vip", mdlBuilder('v')).Get("/", handler)
+
+	testCases := []struct {
+		name       string
+		path       string
+		wantResp   string
+		wantStable bool
+	}{
+		{
+			name:       "param",
+			path:       "/user/1",
+			wantResp:   "uh",
+			wantStable: true,
+		},
+		{
+			name:       "regexp",
+			path:       "/user/1/order/2",
+			wantResp:   "uh",
+			wantStable: true,
+		},
+		{
+			name:       "star",
+			path:       "/file/a/b",
+			wantResp:   "h",
+			wantStable: true,
+		},
+		{
+			name:     "param with static sibling",
+			path:     "/order/1",
+			wantResp: "h",
+		},
+		{
+			name:     "static sibling",
+			path:     "/order/vip",
+			wantResp: "vh",
+			// 静态路由总是确定的
+			wantStable: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			for i := 0; i < 2; i++ {
+				req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+				recorder := httptest.NewRecorder()
+				s.ServeHTTP(recorder, req)
+				assert.Equal(t, tc.wantResp, recorder.Body.String())
+			}
+			mi, ok := s.findRoute(http.MethodGet, tc.path)
+			assert.True(t, ok)
+			assert.Equal(t, tc.wantStable, mi.n.mdlsStable)
+			if tc.wantStable && len(mi.mdls) > 0 {
+				// 后续的请求直接使用缓存的结果
+				assert.Same(t, &mi.n.matchedMdls[0], &mi.mdls[0])
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package web
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Event is a Server-Sent Event, the empty fields are omitted
+type Event struct {
+	ID    string
+	Event string
+	// Data can contain multiple lines
+	Data string
+	// Retry tells the client how long to wait before reconnecting
+	Retry time.Duration
+}
+
+// EventStream writes the events to the client, it is safe for concurrent use
+type EventStream struct {
+	ctx *Context
+	mu  sync.Mutex
+}
+
+// SSE responds with the event stream, and runs fn to send the events.
+// A comment line is sent every heartbeat to keep the connection alive, heartbeat <= 0 disables it.
+// The stream ends when fn returns, fn should return when the client disconnects, see EventStream.Done
+func (c *Context) SSE(heartbeat time.Duration, fn func(stream *EventStream) error) error {
+	header := c.Resp.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	// disable the buffering of nginx
+	header.Set("X-Accel-Buffering", "no")
+	c.RespStatusCode = http.StatusOK
+	c.Flush()
+
+	stream := &EventStream{ctx: c}
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	if heartbeat > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			stream.heartbeat(heartbeat, stop)
+		}()
+	}
+	defer func() {
+		// nothing can be written after the handler returns
+		close(stop)
+		wg.Wait()
+	}()
+	return fn(stream)
+}
+
+// Done is closed when the client disconnects
+func (s *EventStream) Done() <-chan struct{} {
+	return s.ctx.Req.Context().Done()
+}
+
+// Send writes ev and flushes it to the client,
+// it returns the error of the request context if the client has disconnected
+func (s *EventStream) Send(ev Event) error {
+	var sb strings.Builder
+	if ev.ID != "" {
+		writeField(&sb, "id", ev.ID)
+	}
+	if ev.Event != "" {
+		writeField(&sb, "event", ev.Event)
+	}
+	if ev.Retry > 0 {
+		writeField(&sb, "retry", strconv.FormatInt(ev.Retry.Milliseconds(), 10))
+	}
+	for _, line := range strings.Split(ev.Data, "\n") {
+		writeField(&sb, "data", line)
+	}
+	sb.WriteByte('\n')
+	return s.write(sb.String())
+}
+
+func (s *EventStream) heartbeat(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if s.write(": heartbeat\n\n") != nil {
+				return
+			}
+		case <-stop:
+			return
+		case <-s.Done():
+			return
+		}
+	}
+}
+
+func (s *EventStream) write(data string) error {
+	if err := s.ctx.Req.Context().Err(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.ctx.Write([]byte(data)); err != nil {
+		return err
+	}
+	s.ctx.Flush()
+	return nil
+}
+
+var lineBreakRemover = strings.NewReplacer("\r", "", "\n", "")
+
+// writeField writes "name: value\n", the line breaks in value are removed since they end the field
+func writeField(sb *strings.Builder, name string, value string) {
+	sb.WriteString(name)
+	sb.WriteString(": ")
+	sb.WriteString(lineBreakRemover.Replace(value))
+	sb.WriteByte('\n')
+}
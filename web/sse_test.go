@@ -0,0 +1,103 @@
+package web
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContext_SSE(t *testing.T) {
+	s := NewHTTPServer()
+	finished := make(chan error, 1)
+	s.Get("/events", func(ctx *Context) {
+		finished <- ctx.SSE(10*time.Millisecond, func(stream *EventStream) error {
+			err := stream.Send(Event{ID: "1", Event: "progress", Data: "line1\nline2", Retry: time.Second})
+			if err != nil {
+				return err
+			}
+			// 等待客户端断开
+			<-stream.Done()
+			return stream.Send(Event{Data: "after disconnect"})
+		})
+	})
+	server := httptest.NewServer(s)
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/events", nil)
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	assert.Equal(t, "no-cache", resp.Header.Get("Cache-Control"))
+
+	reader := bufio.NewReader(resp.Body)
+	var lines []string
+	for i := 0; i < 7; i++ {
+		line, er := reader.ReadString('\n')
+		require.NoError(t, er)
+		lines = append(lines, line)
+	}
+	assert.Equal(t, []string{
+		"id: 1\n", "event: progress\n", "retry: 1000\n", "data: line1\n", "data: line2\n", "\n",
+		// 心跳
+		": heartbeat\n",
+	}, lines)
+
+	// 客户端断开之后，Send 返回错误
+	cancel()
+	select {
+	case err = <-finished:
+		assert.ErrorIs(t, err, context.Canceled)
+	case <-time.After(time.Second):
+		t.Fatal("the handler does not return after the client disconnects")
+	}
+}
+
+// 推送进度的例子
+func ExampleContext_SSE() {
+	s := NewHTTPServer()
+	s.Get("/progress", func(ctx *Context) {
+		_ = ctx.SSE(15*time.Second, func(stream *EventStream) error {
+			for i := 0; i <= 100; i += 50 {
+				select {
+				case <-stream.Done():
+					return nil
+				default:
+				}
+				err := stream.Send(Event{ID: strconv.Itoa(i), Event: "progress", Data: strconv.Itoa(i) + "%"})
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	})
+
+	recorder := httptest.NewRecorder()
+	s.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/progress", nil))
+	fmt.Print(recorder.Body.String())
+	// Output:
+	// id: 0
+	// event: progress
+	// data: 0%
+	//
+	// id: 50
+	// event: progress
+	// data: 50%
+	//
+	// id: 100
+	// event: progress
+	// data: 100%
+}
@@ -0,0 +1,131 @@
+package web
+
+import (
+	"crypto/tls"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+// ServerWithTLSConfig sets the tls.Config used by StartTLS,
+// the certificate files can be empty if config provides the certificates
+func ServerWithTLSConfig(config *tls.Config) HTTPServerOption {
+	return func(server *HTTPServer) {
+		server.srv.TLSConfig = config
+	}
+}
+
+// ServerWithCertReload makes StartTLS check the certificate files every interval,
+// and reload them when they are modified, so that the renewed certificate is used without restart
+func ServerWithCertReload(interval time.Duration) HTTPServerOption {
+	return func(server *HTTPServer) {
+		server.certReload = interval
+	}
+}
+
+// StartTLS is like Start, but serves HTTPS with certFile and keyFile.
+// HTTP/2 is enabled unless the NextProtos of the tls.Config excludes it
+func (s *HTTPServer) StartTLS(addr string, certFile string, keyFile string) error {
+	if s.certReload > 0 && certFile != "" {
+		certs, err := newCertReloader(certFile, keyFile, s.certReload)
+		if err != nil {
+			return err
+		}
+		// start returns when the server is shut down or fails to start
+		defer certs.close()
+		config := &tls.Config{}
+		if s.srv.TLSConfig != nil {
+			config = s.srv.TLSConfig.Clone()
+		}
+		config.GetCertificate = certs.getCertificate
+		s.srv.TLSConfig = config
+		// the certificate is provided by GetCertificate
+		certFile, keyFile = "", ""
+	}
+	return s.start(addr, nil, func(l net.Listener) error {
+		return s.srv.ServeTLS(l, certFile, keyFile)
+	})
+}
+
+// certReloader polls the modification time of the certificate files,
+// the previous certificate is kept if the new one fails to load
+type certReloader struct {
+	certFile string
+	keyFile  string
+	cert     atomic.Pointer[tls.Certificate]
+	modTime  time.Time
+	done     chan struct{}
+}
+
+func newCertReloader(certFile string, keyFile string, interval time.Duration) (*certReloader, error) {
+	r := &certReloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+		done:     make(chan struct{}),
+	}
+	if err := r.reload(); err != nil {
+		return nil, err
+	}
+	go r.run(interval)
+	return r, nil
+}
+
+func (r *certReloader) run(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			modTime, err := r.lastModTime()
+			if err != nil || !modTime.After(r.modTime) {
+				continue
+			}
+			if err = r.reload(); err != nil {
+				log.Println("web: failed to reload certificate", err)
+			}
+		case <-r.done:
+			return
+		}
+	}
+}
+
+func (r *certReloader) reload() error {
+	modTime, err := r.lastModTime()
+	if err != nil {
+		return err
+	}
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("web: failed to load certificate: %w", err)
+	}
+	r.cert.Store(&cert)
+	r.modTime = modTime
+	return nil
+}
+
+// lastModTime returns the later modification time of the two files
+func (r *certReloader) lastModTime() (time.Time, error) {
+	certInfo, err := os.Stat(r.certFile)
+	if err != nil {
+		return time.Time{}, err
+	}
+	keyInfo, err := os.Stat(r.keyFile)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if keyInfo.ModTime().After(certInfo.ModTime()) {
+		return keyInfo.ModTime(), nil
+	}
+	return certInfo.ModTime(), nil
+}
+
+func (r *certReloader) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return r.cert.Load(), nil
+}
+
+func (r *certReloader) close() {
+	close(r.done)
+}
@@ -0,0 +1,133 @@
+package web
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/http2"
+)
+
+func TestHTTPServer_StartTLS(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	writeCert(t, certFile, keyFile, 1)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	s := NewHTTPServer(ServerWithListener(l), ServerWithCertReload(10*time.Millisecond))
+	s.Get("/", func(ctx *Context) {
+		ctx.RespData = []byte(ctx.Req.Proto)
+	})
+	startErr := make(chan error, 1)
+	go func() {
+		startErr <- s.StartTLS("", certFile, keyFile)
+	}()
+
+	client := &http.Client{Transport: &http.Transport{
+		TLSClientConfig:   &tls.Config{InsecureSkipVerify: true},
+		ForceAttemptHTTP2: true,
+	}}
+	serial := func() int64 {
+		resp, er := client.Get("https://" + l.Addr().String() + "/")
+		require.NoError(t, er)
+		defer resp.Body.Close()
+		// TLS 默认启用 HTTP/2
+		assert.Equal(t, "HTTP/2.0", resp.Proto)
+		return resp.TLS.PeerCertificates[0].SerialNumber.Int64()
+	}
+	assert.Equal(t, int64(1), serial())
+
+	// 证书更新之后自动加载
+	time.Sleep(10 * time.Millisecond)
+	writeCert(t, certFile, keyFile, 2)
+	client.CloseIdleConnections()
+	assert.Eventually(t, func() bool {
+		client.CloseIdleConnections()
+		return serial() == 2
+	}, time.Second, 20*time.Millisecond)
+
+	require.NoError(t, s.Shutdown(context.Background()))
+	assert.NoError(t, <-startErr)
+}
+
+func TestHTTPServer_StartTLSFailed(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	writeCert(t, certFile, keyFile, 1)
+
+	hookErr := errors.New("hook error")
+	s := NewHTTPServer(ServerWithCertReload(time.Millisecond))
+	s.OnStart(func(ctx context.Context) error {
+		return hookErr
+	})
+	assert.ErrorIs(t, s.StartTLS("127.0.0.1:0", certFile, keyFile), hookErr)
+	// the goroutine reloading the certificate stops
+	assert.Eventually(t, func() bool {
+		buf := make([]byte, 1<<20)
+		return !strings.Contains(string(buf[:runtime.Stack(buf, true)]), "certReloader).run")
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestHTTPServer_H2C(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	s := NewHTTPServer(ServerWithH2C(), ServerWithReadTimeout(time.Second))
+	s.Get("/", func(ctx *Context) {
+		ctx.RespData = []byte(ctx.Req.Proto)
+	})
+	startErr := make(chan error, 1)
+	go func() {
+		startErr <- s.Serve(l)
+	}()
+
+	client := &http.Client{Transport: &http2.Transport{
+		AllowHTTP: true,
+		DialTLS: func(network, addr string, _ *tls.Config) (net.Conn, error) {
+			return net.Dial(network, addr)
+		},
+	}}
+	resp, err := client.Get("http://" + l.Addr().String() + "/")
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, "HTTP/2.0", resp.Proto)
+
+	require.NoError(t, s.Shutdown(context.Background()))
+	assert.NoError(t, <-startErr)
+}
+
+// writeCert writes a self-signed certificate whose serial number is serial
+func writeCert(t *testing.T, certFile string, keyFile string, serial int64) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tpl := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tpl, tpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600))
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+}